@@ -0,0 +1,35 @@
+package adaptive
+
+var _ Algorithm = (*AIMD)(nil)
+
+// AIMD additive increase, multiplicative decrease algorithm.
+// the limit increased by one on success, and multiplied by the backoff ratio
+// when a request dropped or its latency exceeds the timeout.
+type AIMD struct {
+	opt   options
+	limit float64
+}
+
+// NewAIMD returns an AIMD algorithm.
+func NewAIMD(opts ...Option) *AIMD {
+	o := newOptions(1, opts...)
+	return &AIMD{
+		opt:   o,
+		limit: float64(o.initialLimit),
+	}
+}
+
+// Limit returns the current limit.
+func (a *AIMD) Limit() int { return int(a.limit) }
+
+// Update updates the limit with a sample, and returns the new limit.
+func (a *AIMD) Update(s Sample) int {
+	if s.Dropped || s.RTT > a.opt.timeout {
+		a.limit = a.opt.clamp(float64(int(a.limit * a.opt.backoffRatio)))
+	} else if s.InFlight*2 >= int(a.limit) {
+		// only increase the limit when the limiter is actually used,
+		// otherwise the limit grows without bound under low load.
+		a.limit = a.opt.clamp(a.limit + 1)
+	}
+	return int(a.limit)
+}
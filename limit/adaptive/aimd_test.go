@@ -0,0 +1,23 @@
+package adaptive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAIMD(t *testing.T) {
+	a := NewAIMD(WithInitialLimit(10), WithMinLimit(5), WithTimeout(time.Second))
+	assert.Equal(t, 10, a.Limit())
+
+	// app limited
+	assert.Equal(t, 10, a.Update(Sample{RTT: time.Millisecond, InFlight: 1}))
+	assert.Equal(t, 11, a.Update(Sample{RTT: time.Millisecond, InFlight: 10}))
+	assert.Equal(t, 9, a.Update(Sample{RTT: time.Millisecond, InFlight: 10, Dropped: true}))
+	assert.Equal(t, 8, a.Update(Sample{RTT: 2 * time.Second, InFlight: 10}))
+	for i := 0; i < 10; i++ {
+		a.Update(Sample{RTT: time.Millisecond, Dropped: true})
+	}
+	assert.Equal(t, 5, a.Limit())
+}
@@ -0,0 +1,71 @@
+package adaptive
+
+import (
+	"math"
+)
+
+var _ Algorithm = (*Gradient2)(nil)
+
+// gradient2WarmupSamples the long-term latency is the simple average of the first samples.
+const gradient2WarmupSamples = 10
+
+// Gradient2 gradient based algorithm, it compares the short-term latency
+// with the long-term exponential average latency:
+//
+//	gradient = clamp(tolerance * longRTT / shortRTT, 0.5, 1.0)
+//	limit    = limit * gradient + queueSize
+//
+// the limit is decreased when the latency increases, and grows by the queue size otherwise.
+// the limit is multiplied by the backoff ratio when a request dropped.
+type Gradient2 struct {
+	opt     options
+	limit   float64
+	longRTT float64
+	samples int
+}
+
+// NewGradient2 returns a Gradient2 algorithm.
+func NewGradient2(opts ...Option) *Gradient2 {
+	o := newOptions(0.2, opts...)
+	return &Gradient2{
+		opt:   o,
+		limit: float64(o.initialLimit),
+	}
+}
+
+// Limit returns the current limit.
+func (g *Gradient2) Limit() int { return int(g.limit) }
+
+// Update updates the limit with a sample, and returns the new limit.
+func (g *Gradient2) Update(s Sample) int {
+	if s.Dropped {
+		g.limit = g.opt.clamp(g.limit * g.opt.backoffRatio)
+		return int(g.limit)
+	}
+	if s.RTT <= 0 {
+		return int(g.limit)
+	}
+	shortRTT := float64(s.RTT)
+	g.samples++
+	if g.samples <= gradient2WarmupSamples {
+		g.longRTT += (shortRTT - g.longRTT) / float64(g.samples)
+	} else {
+		factor := 2 / float64(g.opt.longWindow+1)
+		g.longRTT = g.longRTT*(1-factor) + shortRTT*factor
+	}
+	// the long-term latency is much larger than the short-term,
+	// accelerate the recovery after a period of the high latency.
+	if g.longRTT/shortRTT > 2 {
+		g.longRTT *= 0.95
+	}
+
+	// app limited
+	if s.InFlight*2 < int(g.limit) {
+		return int(g.limit)
+	}
+
+	gradient := math.Max(0.5, math.Min(1.0, g.opt.tolerance*g.longRTT/shortRTT))
+	newLimit := g.opt.clamp(g.limit*gradient + float64(g.opt.queueSize))
+	g.limit = g.opt.clamp((1-g.opt.smoothing)*g.limit + g.opt.smoothing*newLimit)
+	return int(g.limit)
+}
@@ -0,0 +1,47 @@
+package adaptive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGradient2(t *testing.T) {
+	g := NewGradient2(WithInitialLimit(20), WithSmoothing(1))
+	// steady latency, grows by the queue size.
+	assert.Equal(t, 24, g.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 20}))
+	// app limited
+	assert.Equal(t, 24, g.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 1}))
+
+	// warm up the long-term latency.
+	for i := 0; i < 8; i++ {
+		g.Update(Sample{RTT: 10 * time.Millisecond, InFlight: g.Limit()})
+	}
+	assert.Equal(t, 56, g.Limit())
+
+	// latency increased, the limit halves.
+	assert.Equal(t, 32, g.Update(Sample{RTT: 100 * time.Millisecond, InFlight: 56}))
+}
+
+func TestGradient2_Dropped(t *testing.T) {
+	g := NewGradient2(WithInitialLimit(20), WithMinLimit(5), WithBackoffRatio(0.5))
+	// the dropped requests decrease the limit, even if the latency is low.
+	assert.Equal(t, 10, g.Update(Sample{RTT: time.Millisecond, InFlight: 20, Dropped: true}))
+	for i := 0; i < 50; i++ {
+		g.Update(Sample{RTT: time.Millisecond, InFlight: g.Limit(), Dropped: true})
+	}
+	assert.Equal(t, 5, g.Limit())
+}
+
+func TestGradient2_Bound(t *testing.T) {
+	g := NewGradient2(WithInitialLimit(10), WithMinLimit(5), WithMaxLimit(15), WithSmoothing(1))
+	for i := 0; i < 10; i++ {
+		g.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 15})
+	}
+	assert.Equal(t, 15, g.Limit())
+	for i := 0; i < 10; i++ {
+		g.Update(Sample{RTT: time.Second, InFlight: 15, Dropped: true})
+	}
+	assert.GreaterOrEqual(t, g.Limit(), 5)
+}
@@ -0,0 +1,125 @@
+// Package adaptive implements an adaptive concurrency limiter,
+// the limit is adjusted from the observed latency and errors by a pluggable Algorithm,
+// such as AIMD, Vegas and Gradient2, like Netflix concurrency-limits.
+package adaptive
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sample is the result of a request fed back to the Algorithm.
+type Sample struct {
+	// RTT the round trip time of the request.
+	RTT time.Duration
+	// InFlight the number of in-flight requests when the request acquired, include itself.
+	InFlight int
+	// Dropped reports whether the request dropped, such as timeout, rejected by the downstream.
+	Dropped bool
+}
+
+// Algorithm computes the concurrency limit from the samples.
+// NOTE: the Limiter serializes the calls, the Algorithm need not be safe for concurrent use.
+type Algorithm interface {
+	// Limit returns the current limit.
+	Limit() int
+	// Update updates the limit with a sample, and returns the new limit.
+	Update(s Sample) int
+}
+
+// Token is the permit of a request acquired from the Limiter,
+// exactly one of the methods must be called when the request completed.
+type Token interface {
+	// Success the request succeeded, its latency is used to adjust the limit.
+	Success()
+	// Dropped the request dropped, such as timeout, rejected by the downstream, the limit will be decreased.
+	Dropped()
+	// Ignore the request is ignored, such as failed before doing any actual work, it only releases the permit.
+	Ignore()
+}
+
+// Limiter is an adaptive concurrency limiter.
+type Limiter struct {
+	mu       sync.Mutex
+	algo     Algorithm
+	limit    int
+	inFlight int
+}
+
+// New returns a Limiter which limit is adjusted by algo.
+func New(algo Algorithm) *Limiter {
+	return &Limiter{
+		algo:  algo,
+		limit: algo.Limit(),
+	}
+}
+
+// Acquire acquires a Token, it does not block, reports false if the in-flight requests
+// reached the limit or the ctx is done.
+func (l *Limiter) Acquire(ctx context.Context) (Token, bool) {
+	if ctx.Err() != nil {
+		return nil, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inFlight >= l.limit {
+		return nil, false
+	}
+	l.inFlight++
+	return &token{
+		l:        l,
+		start:    time.Now(),
+		inFlight: l.inFlight,
+	}, true
+}
+
+// Limit returns the current limit.
+func (l *Limiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// InFlight returns the number of in-flight requests.
+func (l *Limiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+func (l *Limiter) release(s *Sample) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	if s != nil {
+		l.limit = max(l.algo.Update(*s), 1)
+	}
+}
+
+type token struct {
+	l        *Limiter
+	start    time.Time
+	inFlight int
+	done     atomic.Bool
+}
+
+func (t *token) Success() { t.release(false) }
+func (t *token) Dropped() { t.release(true) }
+func (t *token) Ignore() {
+	if t.done.CompareAndSwap(false, true) {
+		t.l.release(nil)
+	}
+}
+
+func (t *token) release(dropped bool) {
+	if !t.done.CompareAndSwap(false, true) {
+		return
+	}
+	t.l.release(&Sample{
+		RTT:      time.Since(t.start),
+		InFlight: t.inFlight,
+		Dropped:  dropped,
+	})
+}
@@ -0,0 +1,68 @@
+package adaptive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAlgorithm struct {
+	limit   int
+	samples []Sample
+}
+
+func (f *fakeAlgorithm) Limit() int { return f.limit }
+func (f *fakeAlgorithm) Update(s Sample) int {
+	f.samples = append(f.samples, s)
+	if s.Dropped {
+		f.limit--
+	}
+	return f.limit
+}
+
+func TestLimiter(t *testing.T) {
+	algo := &fakeAlgorithm{limit: 2}
+	l := New(algo)
+
+	t1, ok := l.Acquire(context.Background())
+	require.True(t, ok)
+	t2, ok := l.Acquire(context.Background())
+	require.True(t, ok)
+	_, ok = l.Acquire(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, 2, l.InFlight())
+
+	t1.Success()
+	t1.Success() // no effect
+	assert.Equal(t, 1, l.InFlight())
+	require.Len(t, algo.samples, 1)
+	assert.Equal(t, 1, algo.samples[0].InFlight)
+	assert.False(t, algo.samples[0].Dropped)
+
+	t2.Ignore()
+	assert.Equal(t, 0, l.InFlight())
+	assert.Len(t, algo.samples, 1)
+
+	t3, ok := l.Acquire(context.Background())
+	require.True(t, ok)
+	t3.Dropped()
+	require.Len(t, algo.samples, 2)
+	assert.True(t, algo.samples[1].Dropped)
+	assert.Equal(t, 1, l.Limit())
+
+	_, ok = l.Acquire(context.Background())
+	require.True(t, ok)
+	_, ok = l.Acquire(context.Background())
+	assert.False(t, ok)
+}
+
+func TestLimiter_ContextDone(t *testing.T) {
+	l := New(&fakeAlgorithm{limit: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, ok := l.Acquire(ctx)
+	assert.False(t, ok)
+	assert.Zero(t, l.InFlight())
+}
@@ -0,0 +1,136 @@
+package adaptive
+
+import (
+	"math"
+	"time"
+)
+
+// Option algorithm option
+type Option func(*options)
+
+type options struct {
+	initialLimit int
+	minLimit     int
+	maxLimit     int
+	backoffRatio float64
+	timeout      time.Duration
+	smoothing    float64
+	tolerance    float64
+	longWindow   int
+	queueSize    int
+}
+
+func newOptions(smoothing float64, opts ...Option) options {
+	o := options{
+		initialLimit: 20,
+		minLimit:     1,
+		maxLimit:     1000,
+		backoffRatio: 0.9,
+		timeout:      5 * time.Second,
+		smoothing:    smoothing,
+		tolerance:    1.5,
+		longWindow:   600,
+		queueSize:    4,
+	}
+	for _, f := range opts {
+		f(&o)
+	}
+	o.maxLimit = max(o.maxLimit, o.minLimit)
+	o.initialLimit = min(max(o.initialLimit, o.minLimit), o.maxLimit)
+	return o
+}
+
+func (o *options) clamp(limit float64) float64 {
+	return math.Min(math.Max(limit, float64(o.minLimit)), float64(o.maxLimit))
+}
+
+// WithInitialLimit set the initial limit.
+// default: 20
+func WithInitialLimit(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.initialLimit = n
+		}
+	}
+}
+
+// WithMinLimit set the min limit.
+// default: 1
+func WithMinLimit(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.minLimit = n
+		}
+	}
+}
+
+// WithMaxLimit set the max limit.
+// default: 1000
+func WithMaxLimit(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxLimit = n
+		}
+	}
+}
+
+// WithBackoffRatio set the ratio the limit multiplied by when a request dropped, only for AIMD and Gradient2.
+// default: 0.9
+func WithBackoffRatio(r float64) Option {
+	return func(o *options) {
+		if r > 0 && r < 1 {
+			o.backoffRatio = r
+		}
+	}
+}
+
+// WithTimeout set the latency above which a request treated as dropped, only for AIMD.
+// default: 5s
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+// WithSmoothing set the smoothing factor of the limit change, in (0, 1], only for Vegas and Gradient2.
+// default: 1.0 for Vegas, 0.2 for Gradient2
+func WithSmoothing(f float64) Option {
+	return func(o *options) {
+		if f > 0 && f <= 1 {
+			o.smoothing = f
+		}
+	}
+}
+
+// WithTolerance set the tolerance of the latency increase before decreasing the limit, only for Gradient2.
+// such as 2.0 means the limit is not decreased until the latency doubled.
+// default: 1.5
+func WithTolerance(f float64) Option {
+	return func(o *options) {
+		if f >= 1 {
+			o.tolerance = f
+		}
+	}
+}
+
+// WithLongWindow set the number of samples of the long-term latency average, only for Gradient2.
+// default: 600
+func WithLongWindow(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.longWindow = n
+		}
+	}
+}
+
+// WithQueueSize set the number of requests allowed to queue beyond the gradient limit, only for Gradient2.
+// default: 4
+func WithQueueSize(n int) Option {
+	return func(o *options) {
+		if n >= 0 {
+			o.queueSize = n
+		}
+	}
+}
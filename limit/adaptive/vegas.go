@@ -0,0 +1,77 @@
+package adaptive
+
+import (
+	"math"
+	"time"
+)
+
+var _ Algorithm = (*Vegas)(nil)
+
+// vegasProbeMultiplier the no-load latency is probed every vegasProbeMultiplier * limit samples.
+const vegasProbeMultiplier = 30
+
+// Vegas TCP Vegas like algorithm, it estimates the queue size by the ratio
+// of the no-load latency (the min latency) and the current latency:
+//
+//	queue = limit * (1 - rttNoLoad/rtt)
+//
+// the limit is increased when the queue is small, and decreased when the queue is large.
+type Vegas struct {
+	opt        options
+	limit      float64
+	rttNoLoad  time.Duration
+	probeCount int
+}
+
+// NewVegas returns a Vegas algorithm.
+func NewVegas(opts ...Option) *Vegas {
+	o := newOptions(1, opts...)
+	return &Vegas{
+		opt:   o,
+		limit: float64(o.initialLimit),
+	}
+}
+
+// Limit returns the current limit.
+func (v *Vegas) Limit() int { return int(v.limit) }
+
+// Update updates the limit with a sample, and returns the new limit.
+func (v *Vegas) Update(s Sample) int {
+	if s.RTT <= 0 {
+		return int(v.limit)
+	}
+	v.probeCount++
+	// reset the no-load latency periodically, the latency of the downstream may increase permanently.
+	if v.probeCount >= vegasProbeMultiplier*int(v.limit) {
+		v.probeCount = 0
+		v.rttNoLoad = s.RTT
+		return int(v.limit)
+	}
+	if v.rttNoLoad == 0 || s.RTT < v.rttNoLoad {
+		v.rttNoLoad = s.RTT
+		return int(v.limit)
+	}
+
+	logLimit := math.Max(1, math.Log10(v.limit))
+	queueSize := math.Ceil(v.limit * (1 - float64(v.rttNoLoad)/float64(s.RTT)))
+
+	var newLimit float64
+	switch {
+	case s.Dropped:
+		newLimit = v.limit - logLimit
+	case s.InFlight*2 < int(v.limit):
+		// app limited
+		return int(v.limit)
+	case queueSize <= logLimit: // threshold
+		newLimit = v.limit + 6*logLimit // beta
+	case queueSize < 3*logLimit: // alpha
+		newLimit = v.limit + logLimit
+	case queueSize > 6*logLimit: // beta
+		newLimit = v.limit - logLimit
+	default:
+		return int(v.limit)
+	}
+	newLimit = v.opt.clamp(newLimit)
+	v.limit = (1-v.opt.smoothing)*v.limit + v.opt.smoothing*newLimit
+	return int(v.limit)
+}
@@ -0,0 +1,29 @@
+package adaptive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVegas(t *testing.T) {
+	v := NewVegas(WithInitialLimit(10))
+	// no-load latency
+	assert.Equal(t, 10, v.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 10}))
+	// no queue, increase by beta
+	assert.Equal(t, 16, v.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 10}))
+	// app limited
+	assert.Equal(t, 16, v.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 1}))
+	// queue = ceil(16 * (1 - 10/50)) = 13 > beta, decrease
+	assert.Equal(t, 14, v.Update(Sample{RTT: 50 * time.Millisecond, InFlight: 16}))
+	assert.Equal(t, 13, v.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 16, Dropped: true}))
+}
+
+func TestVegas_Bound(t *testing.T) {
+	v := NewVegas(WithInitialLimit(10), WithMaxLimit(12))
+	for i := 0; i < 10; i++ {
+		v.Update(Sample{RTT: 10 * time.Millisecond, InFlight: 12})
+	}
+	assert.Equal(t, 12, v.Limit())
+}
@@ -0,0 +1,66 @@
+package limit
+
+import (
+	"time"
+)
+
+// CalendarUnit the calendar boundary which the window is aligned with, see WithCalendarAlign.
+type CalendarUnit int
+
+const (
+	// CalendarHour the window starts at the start of the hour.
+	CalendarHour CalendarUnit = iota + 1
+	// CalendarDay the window starts at midnight.
+	CalendarDay
+	// CalendarWeek the window starts at midnight of Monday, the ISO 8601 week.
+	CalendarWeek
+	// CalendarMonth the window starts at midnight of the first day of the month.
+	CalendarMonth
+)
+
+// String implements fmt.Stringer.
+func (u CalendarUnit) String() string {
+	switch u {
+	case CalendarHour:
+		return "hour"
+	case CalendarDay:
+		return "day"
+	case CalendarWeek:
+		return "week"
+	case CalendarMonth:
+		return "month"
+	default:
+		return "unknown"
+	}
+}
+
+// Next returns the start of the next window after t in the location,
+// the daylight saving time changes are taken into account.
+func (u CalendarUnit) Next(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	year, month, day := t.Date()
+	switch u {
+	case CalendarHour:
+		next := time.Date(year, month, day, t.Hour(), 0, 0, 0, loc).Add(time.Hour)
+		if !next.After(t) { // the repeated hour when daylight saving time ends.
+			next = next.Add(time.Hour)
+		}
+		return next
+	case CalendarDay:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+	case CalendarWeek:
+		// days since Monday
+		days := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-days+7, 0, 0, 0, 0, loc)
+	case CalendarMonth:
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+	default:
+		return t
+	}
+}
+
+// calendarExpireSeconds returns the seconds until the start of the next window, at least one second.
+func calendarExpireSeconds(now time.Time, unit CalendarUnit, loc *time.Location) int {
+	d := unit.Next(now, loc).Sub(now)
+	return max(int((d+time.Second-1)/time.Second), 1)
+}
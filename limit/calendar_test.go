@@ -0,0 +1,94 @@
+package limit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarUnit_Next(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		unit CalendarUnit
+		loc  *time.Location
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "hour",
+			loc:  shanghai,
+			unit: CalendarHour,
+			now:  time.Date(2025, 1, 31, 23, 59, 59, 0, shanghai),
+			want: time.Date(2025, 2, 1, 0, 0, 0, 0, shanghai),
+		},
+		{
+			name: "day in another location",
+			loc:  shanghai,
+			unit: CalendarDay,
+			now:  time.Date(2025, 1, 31, 16, 30, 0, 0, time.UTC), // 2025-02-01 00:30 in Shanghai
+			want: time.Date(2025, 2, 2, 0, 0, 0, 0, shanghai),
+		},
+		{
+			name: "week on Sunday",
+			loc:  shanghai,
+			unit: CalendarWeek,
+			now:  time.Date(2025, 3, 9, 12, 0, 0, 0, shanghai),
+			want: time.Date(2025, 3, 10, 0, 0, 0, 0, shanghai),
+		},
+		{
+			name: "week on Monday",
+			loc:  shanghai,
+			unit: CalendarWeek,
+			now:  time.Date(2025, 3, 10, 0, 0, 0, 0, shanghai),
+			want: time.Date(2025, 3, 17, 0, 0, 0, 0, shanghai),
+		},
+		{
+			name: "month",
+			loc:  shanghai,
+			unit: CalendarMonth,
+			now:  time.Date(2025, 12, 15, 8, 0, 0, 0, shanghai),
+			want: time.Date(2026, 1, 1, 0, 0, 0, 0, shanghai),
+		},
+		{
+			name: "day when daylight saving time starts",
+			loc:  newYork,
+			unit: CalendarDay,
+			now:  time.Date(2025, 3, 9, 0, 0, 0, 0, newYork),
+			want: time.Date(2025, 3, 10, 0, 0, 0, 0, newYork),
+		},
+		{
+			name: "first hour when daylight saving time ends",
+			loc:  newYork,
+			unit: CalendarHour,
+			now:  time.Date(2025, 11, 2, 5, 30, 0, 0, time.UTC), // the first 01:30 in New York
+			want: time.Date(2025, 11, 2, 6, 0, 0, 0, time.UTC),  // the second 01:00 in New York
+		},
+		{
+			name: "repeated hour when daylight saving time ends",
+			loc:  newYork,
+			unit: CalendarHour,
+			now:  time.Date(2025, 11, 2, 6, 30, 0, 0, time.UTC), // the second 01:30 in New York
+			want: time.Date(2025, 11, 2, 7, 0, 0, 0, time.UTC),  // 02:00 in New York
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.unit.Next(tt.now, tt.loc)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
+
+func TestCalendarExpireSeconds(t *testing.T) {
+	now := time.Date(2025, 1, 31, 23, 59, 59, 500_000_000, time.UTC)
+	assert.Equal(t, 1, calendarExpireSeconds(now, CalendarDay, time.UTC))
+	assert.Equal(t, 1, calendarExpireSeconds(now.Add(499*time.Millisecond), CalendarHour, time.UTC))
+	assert.Equal(t, 2*24*3600+1, calendarExpireSeconds(now, CalendarWeek, time.UTC))
+}
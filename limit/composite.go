@@ -0,0 +1,166 @@
+package limit
+
+import (
+	"context"
+	"errors"
+)
+
+// CompositeMember a member of CompositeLimit.
+type CompositeMember struct {
+	Driver PeriodLimitDriver
+	Key    string
+	// Cost the permits to take, zero means 1.
+	Cost int
+}
+
+// CompositeResult the result of CompositeLimit take.
+type CompositeResult struct {
+	// State the permit state, over quota if any member blocked, hit quota if any member hit quota.
+	State PeriodLimitState
+	// Blocked the index of the first member which blocked the request, -1 if allowed.
+	Blocked int
+	// Members the result of each member in order.
+	// NOTE: when the members are not taken in one batch, the members behind the blocked one
+	// are not evaluated with unknown state, and the ones before it are refunded.
+	Members []TakeResult
+}
+
+// CompositeLimit takes permits from several limiters and keys, all or nothing.
+// such as a login endpoint must pass both the per-ip and the per-account PeriodLimit.
+//
+// If all the members are PeriodLimit with the same PeriodBatchStorage, such as the PeriodStore of
+// the same redis client, and their keys are different, they are decided atomically in one round trip,
+// otherwise the members are taken in order, the taken ones are refunded if a member blocked, so all
+// the members must support refund, it returns ErrRefundUnsupported without taking any permit if not,
+// see PeriodRefundStorage.
+// NOTE: the keys of a batch are not hash tagged, so the batch needs all the keys in one redis node,
+// it does not work with redis cluster.
+type CompositeLimit []CompositeMember
+
+// Take requests the permits of all the members.
+func (c CompositeLimit) Take(ctx context.Context) (*CompositeResult, error) {
+	for _, m := range c {
+		if m.Cost < 0 {
+			return nil, ErrInvalidCost
+		}
+	}
+	if r, ok, err := c.takeBatch(ctx); ok {
+		return r, err
+	}
+	return c.takeEach(ctx)
+}
+
+// refundablePeriodLimit is implemented by PeriodLimit, the drivers which do not implement it
+// are assumed to support refund.
+type refundablePeriodLimit interface {
+	CanRefund() bool
+}
+
+// batchPeriodLimit is implemented by PeriodLimit.
+type batchPeriodLimit interface {
+	batchSpec(ctx context.Context, key string) (store PeriodBatchStorage, fullKey string, quota, expireSec int, ok bool)
+}
+
+// takeBatch takes all the members in one batch, ok is false if they can not be batched.
+// The members with the same key can not be batched, as the script checks each key on its own,
+// the costs of them would be counted against the quota separately.
+func (c CompositeLimit) takeBatch(ctx context.Context) (*CompositeResult, bool, error) {
+	var store PeriodBatchStorage
+
+	seen := make(map[string]struct{}, len(c))
+	keys := make([]string, 0, len(c))
+	quotas := make([]int, 0, len(c))
+	expireSecs := make([]int, 0, len(c))
+	costs := make([]int, 0, len(c))
+	for _, m := range c {
+		b, ok := m.Driver.(batchPeriodLimit)
+		if !ok {
+			return nil, false, nil
+		}
+		s, key, quota, expireSec, ok := b.batchSpec(ctx, m.Key)
+		if !ok || (store != nil && s.BatchKey() != store.BatchKey()) {
+			return nil, false, nil
+		}
+		if _, dup := seen[key]; dup {
+			return nil, false, nil
+		}
+		seen[key] = struct{}{}
+		store = s
+		keys = append(keys, key)
+		quotas = append(quotas, quota)
+		expireSecs = append(expireSecs, expireSec)
+		costs = append(costs, m.cost())
+	}
+	if store == nil {
+		return nil, false, nil
+	}
+	tb, err := store.TakeBatch(ctx, keys, quotas, expireSecs, costs)
+	if err != nil {
+		return nil, true, err
+	}
+	state, blocked, results, err := parseBatchResult(tb, quotas)
+	if err != nil {
+		return nil, true, err
+	}
+	return &CompositeResult{
+		State:   state,
+		Blocked: blocked,
+		Members: results,
+	}, true, nil
+}
+
+// takeEach takes the members in order, and refunds the taken ones if a member blocked or failed.
+func (c CompositeLimit) takeEach(ctx context.Context) (*CompositeResult, error) {
+	for _, m := range c {
+		if r, ok := m.Driver.(refundablePeriodLimit); ok && !r.CanRefund() {
+			return nil, ErrRefundUnsupported
+		}
+	}
+	result := &CompositeResult{
+		State:   PeriodLimitStsAllowed,
+		Blocked: -1,
+		Members: make([]TakeResult, len(c)),
+	}
+	for i := range result.Members {
+		result.Members[i].State = PeriodLimitStsUnknown
+	}
+	for i, m := range c {
+		r, err := m.Driver.TakeNWithResult(ctx, m.Key, m.cost())
+		if err != nil {
+			return nil, errors.Join(err, c.refund(ctx, i))
+		}
+		result.Members[i] = *r
+		switch {
+		case r.State.IsOverQuota():
+			result.State = PeriodLimitStsOverQuota
+			result.Blocked = i
+			if err = c.refund(ctx, i); err != nil {
+				return nil, err
+			}
+			return result, nil
+		case r.State.IsHitQuota():
+			result.State = PeriodLimitStsHitQuota
+		}
+	}
+	return result, nil
+}
+
+// refund gives back the permits of the first n members.
+func (c CompositeLimit) refund(ctx context.Context, n int) error {
+	// refund even if the ctx is canceled, otherwise the quota burned.
+	ctx = context.WithoutCancel(ctx)
+	var errs []error
+	for _, m := range c[:n] {
+		if err := m.Driver.Refund(ctx, m.Key, m.cost()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *CompositeMember) cost() int {
+	if m.Cost == 0 {
+		return 1
+	}
+	return m.Cost
+}
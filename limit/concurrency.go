@@ -0,0 +1,95 @@
+package limit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// A ConcurrencyLimit is used to limit the max in-flight requests per key across instances.
+// Each request holds a lease until released, the lease expires automatically if the holder crashed.
+// The lease expire time is measured by the storage clock, so the replicas with clock skew share the same limit.
+type ConcurrencyLimit[S ConcurrencyStorage] struct {
+	// keyPrefix in store
+	keyPrefix string
+	// max in-flight requests per key.
+	limit int
+	// time to live of a lease.
+	leaseTTL time.Duration
+	store    S
+}
+
+// NewConcurrencyLimit returns a ConcurrencyLimit that allows at most limit in-flight requests per key.
+func NewConcurrencyLimit[S ConcurrencyStorage](store S, limit int, opts ...ConcurrencyLimitOption) *ConcurrencyLimit[S] {
+	limiter := &ConcurrencyLimit[S]{
+		keyPrefix: "limit:concurrency:",
+		limit:     limit,
+		leaseTTL:  time.Minute,
+		store:     store,
+	}
+	for _, opt := range opts {
+		opt(limiter)
+	}
+	return limiter
+}
+
+// Acquire acquires a lease of the key, it returns the lease id,
+// ErrConcurrencyLimitReached if the number of in-flight requests reached the limit.
+func (c *ConcurrencyLimit[S]) Acquire(ctx context.Context, key string) (string, error) {
+	lease, err := newLeaseID()
+	if err != nil {
+		return "", err
+	}
+	ok, err := c.store.Acquire(ctx, c.formatKey(key), lease, c.limit, c.leaseTTL)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrConcurrencyLimitReached
+	}
+	return lease, nil
+}
+
+// Refresh extends the lease, it returns ErrLeaseNotFound if the lease not exist or expired.
+func (c *ConcurrencyLimit[S]) Refresh(ctx context.Context, key, lease string) error {
+	ok, err := c.store.Refresh(ctx, c.formatKey(key), lease, c.leaseTTL)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLeaseNotFound
+	}
+	return nil
+}
+
+// Release releases the lease.
+func (c *ConcurrencyLimit[S]) Release(ctx context.Context, key, lease string) error {
+	return c.store.Release(ctx, c.formatKey(key), lease)
+}
+
+// InFlight returns the number of in-flight requests of the key.
+func (c *ConcurrencyLimit[S]) InFlight(ctx context.Context, key string) (int, error) {
+	n, err := c.store.Count(ctx, c.formatKey(key))
+	return int(n), err
+}
+
+func (c *ConcurrencyLimit[S]) formatKey(key string) string {
+	return c.keyPrefix + key
+}
+
+func (c *ConcurrencyLimit[S]) setKeyPrefix(k string) { c.keyPrefix = k }
+func (c *ConcurrencyLimit[S]) setLeaseTTL(v time.Duration) {
+	if v >= time.Millisecond {
+		c.leaseTTL = v
+	}
+}
+
+// newLeaseID returns a random lease id.
+func newLeaseID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,34 @@
+package limit
+
+import (
+	"strings"
+	"time"
+)
+
+// ConcurrencyLimitOptionSetter option setter for ConcurrencyLimit
+type ConcurrencyLimitOptionSetter interface {
+	setKeyPrefix(k string)
+	setLeaseTTL(v time.Duration)
+}
+
+// ConcurrencyLimitOption defines the method to customize a ConcurrencyLimit.
+type ConcurrencyLimitOption func(l ConcurrencyLimitOptionSetter)
+
+// WithConcurrencyKeyPrefix set key prefix
+func WithConcurrencyKeyPrefix(k string) ConcurrencyLimitOption {
+	return func(l ConcurrencyLimitOptionSetter) {
+		if !strings.HasSuffix(k, ":") {
+			k += ":"
+		}
+		l.setKeyPrefix(k)
+	}
+}
+
+// WithLeaseTTL set the time to live of a lease, the lease of a crashed holder
+// expires automatically after it, the holder should Refresh it in time for long tasks.
+// default: 1 minute
+func WithLeaseTTL(v time.Duration) ConcurrencyLimitOption {
+	return func(l ConcurrencyLimitOptionSetter) {
+		l.setLeaseTTL(v)
+	}
+}
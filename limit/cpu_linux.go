@@ -0,0 +1,73 @@
+//go:build linux
+
+package limit
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NewCPUSampler returns a CPUSampler which reads the cpu usage of the host from /proc/stat.
+// it returns 0 on the first call or if /proc/stat is unavailable.
+// NOTE: the cgroup cpu quota of a container is not considered.
+func NewCPUSampler() CPUSampler {
+	var prevTotal, prevIdle uint64
+	return func() float64 {
+		f, err := os.Open("/proc/stat")
+		if err != nil {
+			return 0
+		}
+		defer f.Close()
+		total, idle, err := parseProcStat(f)
+		if err != nil {
+			return 0
+		}
+		if total <= prevTotal {
+			return 0
+		}
+		first := prevTotal == 0
+		deltaTotal, deltaIdle := total-prevTotal, uint64(0)
+		if idle > prevIdle { // iowait may decrease on some kernels.
+			deltaIdle = idle - prevIdle
+		}
+		prevTotal, prevIdle = total, idle
+		if first {
+			return 0
+		}
+		return 1 - float64(deltaIdle)/float64(deltaTotal)
+	}
+}
+
+// parseProcStat returns the total and the idle (idle + iowait) cpu time from the "cpu" line of /proc/stat.
+func parseProcStat(r io.Reader) (total, idle uint64, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		for i, field := range fields[1:] {
+			v, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, err
+			}
+			// guest and guest_nice are already accounted in user and nice.
+			if i >= 8 {
+				break
+			}
+			total += v
+			if i == 3 || i == 4 { // idle, iowait
+				idle += v
+			}
+		}
+		return total, idle, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	return 0, 0, errors.New("limit: cpu line not found in /proc/stat")
+}
@@ -0,0 +1,21 @@
+package limit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProcStat(t *testing.T) {
+	total, idle, err := parseProcStat(strings.NewReader(
+		"cpu  100 10 50 800 40 0 0 0 20 0\ncpu0 50 5 25 400 20 0 0 0 10 0\nintr 1\n",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), total)
+	assert.Equal(t, uint64(840), idle)
+
+	_, _, err = parseProcStat(strings.NewReader("intr 1\n"))
+	assert.Error(t, err)
+}
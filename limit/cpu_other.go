@@ -0,0 +1,9 @@
+//go:build !linux
+
+package limit
+
+// NewCPUSampler returns a CPUSampler which always returns 0,
+// the cpu usage is only supported on linux, use WithCPUSampler instead.
+func NewCPUSampler() CPUSampler {
+	return func() float64 { return 0 }
+}
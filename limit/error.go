@@ -12,4 +12,22 @@ var (
 	ErrDuplicateDriver = errors.New("limit: duplicate driver")
 	// ErrUnsupportedDriver is an error that driver unsupported.
 	ErrUnsupportedDriver = errors.New("limit: unsupported driver")
+	// ErrStoreUnavailable is an error that the storage is unavailable.
+	ErrStoreUnavailable = errors.New("limit: storage unavailable")
+	// ErrInvalidCost is an error that the cost is not positive.
+	ErrInvalidCost = errors.New("limit: cost must be positive")
+	// ErrRefundUnsupported is an error that the storage does not support refund, see PeriodRefundStorage.
+	ErrRefundUnsupported = errors.New("limit: storage does not support refund")
+	// ErrNoPeriodWindow is an error that there is no period window.
+	ErrNoPeriodWindow = errors.New("limit: no period window")
+	// ErrInvalidPeriodWindow is an error that the period window is invalid.
+	ErrInvalidPeriodWindow = errors.New("limit: invalid period window")
+)
+
+// concurrency limit error
+var (
+	// ErrConcurrencyLimitReached is an error that the number of concurrent leases reached the limit.
+	ErrConcurrencyLimitReached = errors.New("limit: concurrency limit reached")
+	// ErrLeaseNotFound is an error that the lease not exist or expired.
+	ErrLeaseNotFound = errors.New("limit: lease not found or expired")
 )
@@ -0,0 +1,101 @@
+package limit
+
+import (
+	"context"
+	"time"
+)
+
+// GCRAResult the result of GCRALimit.
+type GCRAResult struct {
+	// Allowed reports whether the requests are allowed.
+	Allowed bool
+	// Limit the burst of the limiter.
+	Limit int
+	// Remaining the remaining requests can be allowed at now.
+	Remaining int
+	// RetryAfter the time until the requests will be allowed, -1 if allowed or never allowed.
+	RetryAfter time.Duration
+	// ResetAfter the time until the limiter returns to the initial state.
+	ResetAfter time.Duration
+}
+
+// A GCRALimit is used to limit requests with generic cell rate algorithm.
+// it stores a single theoretical arrival time per key, smooth rate limiting with burst.
+// the time is read from the storage clock, such as the redis server clock,
+// so the replicas with clock skew share the same limit.
+type GCRALimit[S GCRAStorage] struct {
+	// keyPrefix in store
+	keyPrefix string
+	// emission interval of a request, period / rate.
+	emissionInterval time.Duration
+	// burst of requests.
+	burst int
+	store S
+}
+
+// NewGCRALimit returns a GCRALimit that allows rate requests during a period of time.
+func NewGCRALimit[S GCRAStorage](store S, rate int, period time.Duration, opts ...GCRALimitOption) *GCRALimit[S] {
+	if rate <= 0 {
+		rate = 1
+	}
+	limiter := &GCRALimit[S]{
+		keyPrefix:        "limit:gcra:",
+		emissionInterval: period / time.Duration(rate),
+		burst:            rate,
+		store:            store,
+	}
+	if limiter.emissionInterval < time.Microsecond {
+		limiter.emissionInterval = time.Microsecond
+	}
+	for _, opt := range opts {
+		opt(limiter)
+	}
+	return limiter
+}
+
+// Allow is shorthand for AllowN(ctx, key, 1).
+func (g *GCRALimit[S]) Allow(ctx context.Context, key string) (*GCRAResult, error) {
+	return g.AllowN(ctx, key, 1)
+}
+
+// AllowN reports whether n requests may happen now.
+func (g *GCRALimit[S]) AllowN(ctx context.Context, key string, n int) (*GCRAResult, error) {
+	tb, err := g.store.Take(ctx, g.formatKey(key), g.burst, g.emissionInterval, n)
+	if err != nil {
+		return nil, err
+	}
+	if len(tb) != 4 {
+		return nil, ErrUnknownCode
+	}
+	return &GCRAResult{
+		Allowed:    tb[0] == 1,
+		Limit:      g.burst,
+		Remaining:  int(max(tb[1], 0)),
+		RetryAfter: microDuration(tb[2]),
+		ResetAfter: microDuration(tb[3]),
+	}, nil
+}
+
+// Del delete a permit
+func (g *GCRALimit[S]) Del(ctx context.Context, key string) error {
+	return g.store.Del(ctx, g.formatKey(key))
+}
+
+func (g *GCRALimit[S]) formatKey(key string) string {
+	return g.keyPrefix + key
+}
+
+func (g *GCRALimit[S]) setKeyPrefix(k string) { g.keyPrefix = k }
+func (g *GCRALimit[S]) setBurst(v int) {
+	if v > 0 {
+		g.burst = v
+	}
+}
+
+// microDuration returns duration of v microseconds, keep -1 as it is.
+func microDuration(v int64) time.Duration {
+	if v < 0 {
+		return -1
+	}
+	return time.Duration(v) * time.Microsecond
+}
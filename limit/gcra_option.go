@@ -0,0 +1,31 @@
+package limit
+
+import (
+	"strings"
+)
+
+// GCRALimitOptionSetter option setter for GCRALimit
+type GCRALimitOptionSetter interface {
+	setKeyPrefix(k string)
+	setBurst(v int)
+}
+
+// GCRALimitOption defines the method to customize a GCRALimit.
+type GCRALimitOption func(l GCRALimitOptionSetter)
+
+// WithGCRAKeyPrefix set key prefix
+func WithGCRAKeyPrefix(k string) GCRALimitOption {
+	return func(l GCRALimitOptionSetter) {
+		if !strings.HasSuffix(k, ":") {
+			k += ":"
+		}
+		l.setKeyPrefix(k)
+	}
+}
+
+// WithGCRABurst permits bursts of at most v requests, default same as rate.
+func WithGCRABurst(v int) GCRALimitOption {
+	return func(l GCRALimitOptionSetter) {
+		l.setBurst(v)
+	}
+}
@@ -0,0 +1,269 @@
+package limit
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultHealthCheckMaxInterval default max interval of probing the unhealthy storage.
+const DefaultHealthCheckMaxInterval = 5 * time.Second
+
+// HealthCheckerOptionSetter option setter for HealthChecker
+type HealthCheckerOptionSetter interface {
+	setInterval(minInterval, maxInterval time.Duration)
+	setContext(ctx context.Context)
+}
+
+// HealthCheckerOption defines the method to customize a HealthChecker.
+type HealthCheckerOption func(h HealthCheckerOptionSetter)
+
+// WithHealthCheckInterval set the interval of probing the unhealthy storage,
+// it starts with minInterval, and doubles after each failure until maxInterval.
+// default: TokenLimitPingInterval, DefaultHealthCheckMaxInterval
+func WithHealthCheckInterval(minInterval, maxInterval time.Duration) HealthCheckerOption {
+	return func(h HealthCheckerOptionSetter) {
+		h.setInterval(minInterval, maxInterval)
+	}
+}
+
+// WithHealthCheckContext set the context of HealthChecker, it will be closed when the context done.
+func WithHealthCheckContext(ctx context.Context) HealthCheckerOption {
+	return func(h HealthCheckerOptionSetter) {
+		h.setContext(ctx)
+	}
+}
+
+// HealthChecker checks whether a storage is healthy, one HealthChecker can be shared
+// by many limiters of the same storage, so there is only one probe when the storage is unavailable.
+type HealthChecker struct {
+	ping        func(ctx context.Context) error
+	minInterval time.Duration
+	maxInterval time.Duration
+	ctx         context.Context
+	cancel      context.CancelFunc
+	healthy     atomic.Bool
+	mu          sync.Mutex
+	probing     bool
+	closed      bool
+	nextID      listenerID
+	listeners   map[any]*healthListener
+	wg          sync.WaitGroup
+}
+
+// NewHealthChecker returns a HealthChecker which use ping to probe the storage.
+func NewHealthChecker(ping func(ctx context.Context) error, opts ...HealthCheckerOption) *HealthChecker {
+	h := &HealthChecker{
+		ping:        ping,
+		minInterval: TokenLimitPingInterval,
+		maxInterval: DefaultHealthCheckMaxInterval,
+		ctx:         context.Background(),
+		listeners:   make(map[any]*healthListener),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.ctx, h.cancel = context.WithCancel(h.ctx)
+	h.healthy.Store(true)
+	context.AfterFunc(h.ctx, h.Close)
+	return h
+}
+
+// Healthy reports whether the storage is healthy.
+func (h *HealthChecker) Healthy() bool {
+	return h.healthy.Load()
+}
+
+// MarkUnhealthy marks the storage unhealthy, and probes it in background until it is healthy again.
+// it does nothing if it is probing or closed.
+func (h *HealthChecker) MarkUnhealthy(err error) {
+	h.mu.Lock()
+	if h.closed || h.probing {
+		h.mu.Unlock()
+		return
+	}
+	h.probing = true
+	h.healthy.Store(false)
+	listeners := h.snapshotListeners()
+	h.wg.Add(1)
+	h.mu.Unlock()
+
+	go func() {
+		recovered := h.probe()
+		// notify outside the wait group, the listeners may call Close.
+		h.wg.Done()
+		for _, f := range recovered {
+			f(true, nil)
+		}
+	}()
+	for _, f := range listeners {
+		f(false, err)
+	}
+}
+
+// Close stops probing, the storage is considered healthy after closed.
+func (h *HealthChecker) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	h.mu.Unlock()
+
+	h.cancel()
+	h.wg.Wait()
+	h.healthy.Store(true)
+}
+
+// listenerID the key of the listener subscribed without a key.
+type listenerID uint64
+
+// healthListener a listener shared by the subscribers with the same key.
+type healthListener struct {
+	f    func(healthy bool, err error)
+	refs int
+}
+
+// subscribe the health change, it returns a function to unsubscribe.
+// the subscribers with the same non-nil key share the first listener, which is notified once per change.
+func (h *HealthChecker) subscribe(key any, f func(healthy bool, err error)) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if key == nil {
+		key = h.nextID
+		h.nextID++
+	}
+	l, ok := h.listeners[key]
+	if !ok {
+		l = &healthListener{f: f}
+		h.listeners[key] = l
+	}
+	l.refs++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			l.refs--
+			if l.refs == 0 {
+				delete(h.listeners, key)
+			}
+		})
+	}
+}
+
+// probe probes the storage until it is healthy or closed,
+// it returns the listeners to notify if it is healthy again.
+func (h *HealthChecker) probe() []func(healthy bool, err error) {
+	interval := h.minInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-h.ctx.Done():
+			h.mu.Lock()
+			h.probing = false
+			h.mu.Unlock()
+			return nil
+		case <-timer.C:
+		}
+		ctx, cancel := context.WithTimeout(h.ctx, h.maxInterval)
+		err := h.ping(ctx)
+		cancel()
+		if err == nil {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.probing = false
+			h.healthy.Store(true)
+			return h.snapshotListeners()
+		}
+		interval = min(interval*2, h.maxInterval)
+		timer.Reset(interval)
+	}
+}
+
+// snapshotListeners NOTE: the caller must hold the lock.
+func (h *HealthChecker) snapshotListeners() []func(healthy bool, err error) {
+	listeners := make([]func(healthy bool, err error), 0, len(h.listeners))
+	for _, l := range h.listeners {
+		listeners = append(listeners, l.f)
+	}
+	return listeners
+}
+
+func (h *HealthChecker) setInterval(minInterval, maxInterval time.Duration) {
+	if minInterval > 0 {
+		h.minInterval = minInterval
+	}
+	if maxInterval > 0 {
+		h.maxInterval = maxInterval
+	}
+	if h.maxInterval < h.minInterval {
+		h.maxInterval = h.minInterval
+	}
+}
+func (h *HealthChecker) setContext(ctx context.Context) {
+	if ctx != nil {
+		h.ctx = ctx
+	}
+}
+
+// HealthCheckKeyer is an optional interface of TokenStorage,
+// the storages with the same key share one HealthChecker, such as the same redis client.
+type HealthCheckKeyer interface {
+	HealthCheckKey() any
+}
+
+type sharedHealthChecker struct {
+	*HealthChecker
+	refs int
+}
+
+var sharedHealthCheckers = struct {
+	sync.Mutex
+	m map[any]*sharedHealthChecker
+}{
+	m: make(map[any]*sharedHealthChecker),
+}
+
+// acquireHealthChecker returns the shared HealthChecker of the storage,
+// and a function to release it, it will be closed when no one use it.
+func acquireHealthChecker(store TokenStorage) (*HealthChecker, func()) {
+	var key any = store
+	if k, ok := any(store).(HealthCheckKeyer); ok {
+		key = k.HealthCheckKey()
+	}
+	if key == nil || !reflect.TypeOf(key).Comparable() {
+		h := NewHealthChecker(store.Ping)
+		return h, h.Close
+	}
+
+	sharedHealthCheckers.Lock()
+	defer sharedHealthCheckers.Unlock()
+	s, ok := sharedHealthCheckers.m[key]
+	if !ok {
+		s = &sharedHealthChecker{HealthChecker: NewHealthChecker(store.Ping)}
+		sharedHealthCheckers.m[key] = s
+	}
+	s.refs++
+
+	var once sync.Once
+	return s.HealthChecker, func() {
+		once.Do(func() {
+			sharedHealthCheckers.Lock()
+			s.refs--
+			closed := s.refs == 0
+			if closed {
+				delete(sharedHealthCheckers.m, key)
+			}
+			sharedHealthCheckers.Unlock()
+			if closed {
+				s.Close()
+			}
+		})
+	}
+}
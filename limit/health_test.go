@@ -0,0 +1,175 @@
+package limit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keyedFakeTokenStore struct {
+	*fakeTokenStore
+	key string
+}
+
+func (k keyedFakeTokenStore) HealthCheckKey() any { return k.key }
+
+func TestHealthChecker(t *testing.T) {
+	store := &fakeTokenStore{}
+	h := NewHealthChecker(store.Ping, WithHealthCheckInterval(10*time.Millisecond, 40*time.Millisecond))
+	defer h.Close()
+
+	var mu sync.Mutex
+	var events []bool
+	unsubscribe := h.subscribe(nil, func(healthy bool, _ error) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, healthy)
+	})
+	defer unsubscribe()
+
+	assert.True(t, h.Healthy())
+	h.MarkUnhealthy(errStoreUnavailable)
+	h.MarkUnhealthy(errStoreUnavailable) // probing only once
+	assert.False(t, h.Healthy())
+
+	// backoff: 10ms, 20ms, 40ms, 40ms...
+	time.Sleep(150 * time.Millisecond)
+	pings := store.pings.Load()
+	assert.GreaterOrEqual(t, pings, int32(3))
+	assert.LessOrEqual(t, pings, int32(5))
+
+	store.alive.Store(true)
+	require.Eventually(t, h.Healthy, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, []bool{false, true}, events)
+	mu.Unlock()
+}
+
+func TestHealthChecker_Close(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	store := &fakeTokenStore{}
+	h := NewHealthChecker(store.Ping, WithHealthCheckContext(ctx))
+
+	h.MarkUnhealthy(errStoreUnavailable)
+	assert.False(t, h.Healthy())
+
+	// context-driven shutdown stops probing.
+	cancel()
+	require.Eventually(t, h.Healthy, time.Second, 10*time.Millisecond)
+	pings := store.pings.Load()
+	time.Sleep(3 * TokenLimitPingInterval)
+	assert.Equal(t, pings, store.pings.Load())
+
+	// closed, no more probe.
+	h.MarkUnhealthy(errStoreUnavailable)
+	assert.True(t, h.Healthy())
+	h.Close()
+}
+
+func TestHealthChecker_CloseInListener(t *testing.T) {
+	for _, healthy := range []bool{false, true} {
+		store := &fakeTokenStore{}
+		h := NewHealthChecker(store.Ping, WithHealthCheckInterval(10*time.Millisecond, 10*time.Millisecond))
+		closed := make(chan struct{})
+		h.subscribe(nil, func(v bool, _ error) {
+			if v == healthy {
+				h.Close()
+				close(closed)
+			}
+		})
+		h.MarkUnhealthy(errStoreUnavailable)
+		store.alive.Store(true)
+		select {
+		case <-closed:
+		case <-time.After(time.Second):
+			t.Fatalf("deadlock when closing in the listener, healthy: %v", healthy)
+		}
+	}
+}
+
+func TestHealthChecker_Shared(t *testing.T) {
+	store := &fakeTokenStore{}
+	l1 := NewTokenLimit(5, 10, "first", keyedFakeTokenStore{store, "shared"})
+	l2 := NewKeyedTokenLimit(5, 10, keyedFakeTokenStore{store, "shared"})
+	l3 := NewTokenLimit(5, 10, "third", store)
+	assert.Same(t, l1.health, l2.health)
+	assert.NotSame(t, l1.health, l3.health)
+
+	l1.Allow()
+	assert.False(t, l2.isAlive())
+	assert.True(t, l3.isAlive())
+
+	l1.Close()
+	l1.Close()
+	assert.False(t, l2.isAlive())
+
+	// the last one closes the shared HealthChecker.
+	l2.Close()
+	assert.True(t, l2.isAlive())
+	l3.Close()
+
+	sharedHealthCheckers.Lock()
+	assert.NotContains(t, sharedHealthCheckers.m, "shared")
+	sharedHealthCheckers.Unlock()
+}
+
+func TestHealthChecker_SharedDegradedHook(t *testing.T) {
+	var mu sync.Mutex
+	events := make(map[int]int)
+	newHook := func(id int) func(DegradedEvent) {
+		return func(DegradedEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events[id]++
+		}
+	}
+	count := func(id int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return events[id]
+	}
+
+	store := keyedFakeTokenStore{&fakeTokenStore{}, "shared:hook"}
+	l1 := NewTokenLimit(5, 10, "first", store, WithDegradedHook(newHook(1)))
+	l2 := NewTokenLimit(5, 10, "second", store, WithDegradedHook(newHook(2)))
+	defer l2.Close()
+
+	// the closures of the same function literal are different hooks.
+	l1.Allow()
+	assert.Equal(t, 1, count(1))
+	assert.Equal(t, 1, count(2))
+	store.alive.Store(true)
+	require.Eventually(t, func() bool { return count(1) == 2 && count(2) == 2 }, time.Second, 10*time.Millisecond)
+
+	// the closed one is unsubscribed.
+	l1.Close()
+	store.alive.Store(false)
+	l2.Allow()
+	assert.Equal(t, 2, count(1))
+	assert.Equal(t, 3, count(2))
+}
+
+func TestHealthChecker_SharedLogDegradedHook(t *testing.T) {
+	listeners := func(h *HealthChecker) int {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		return len(h.listeners)
+	}
+
+	store := keyedFakeTokenStore{&fakeTokenStore{}, "shared:log"}
+	l1 := NewTokenLimit(5, 10, "first", store)
+	l2 := NewTokenLimit(5, 10, "second", store)
+	l3 := NewTokenLimit(5, 10, "third", store, WithFailurePolicy(FailurePolicyOpen))
+	defer l3.Close()
+
+	// the default log hook is shared per policy.
+	assert.Equal(t, 2, listeners(l1.health))
+	l1.Close()
+	assert.Equal(t, 2, listeners(l2.health))
+	l2.Close()
+	assert.Equal(t, 1, listeners(l3.health))
+}
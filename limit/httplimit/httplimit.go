@@ -0,0 +1,153 @@
+// Package httplimit provides a net/http rate limiting middleware, which emits
+// the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers
+// (draft-ietf-httpapi-ratelimit-headers) and the Retry-After header.
+package httplimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// headers
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+// KeyFunc extracts the limit key from the request.
+type KeyFunc func(r *http.Request) (string, error)
+
+// ErrorHandler handles the error of the KeyFunc or the Limiter.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// Option middleware option
+type Option func(*options)
+
+type options struct {
+	keyFunc       KeyFunc
+	deniedHandler http.Handler
+	errorHandler  ErrorHandler
+}
+
+// WithKeyFunc set the KeyFunc.
+// default: KeyByIP(), the client ip headers are ignored, use KeyByIP with WithTrustedProxies behind proxies.
+func WithKeyFunc(f KeyFunc) Option {
+	return func(o *options) {
+		if f != nil {
+			o.keyFunc = f
+		}
+	}
+}
+
+// WithDeniedHandler set the handler of the denied requests, the rate limit headers
+// are already set, use ResultFromContext to get the Result.
+// default: responds 429 Too Many Requests.
+func WithDeniedHandler(h http.Handler) Option {
+	return func(o *options) {
+		if h != nil {
+			o.deniedHandler = h
+		}
+	}
+}
+
+// WithErrorHandler set the handler of the errors.
+// default: responds 500 Internal Server Error, use a handler which calls the next handler to fail open.
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(o *options) {
+		if h != nil {
+			o.errorHandler = h
+		}
+	}
+}
+
+// KeyByRemoteAddr uses the host of the request remote address as the key,
+// the IPv6 addresses are not grouped, prefer KeyByIP.
+func KeyByRemoteAddr(r *http.Request) (string, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr, nil
+	}
+	return host, nil
+}
+
+func defaultDeniedHandler(w http.ResponseWriter, _ *http.Request) {
+	http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
+
+func defaultErrorHandler(w http.ResponseWriter, _ *http.Request, _ error) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// ErrEmptyKey is an error that the KeyFunc returns an empty key.
+var ErrEmptyKey = errors.New("httplimit: empty key")
+
+type resultKey struct{}
+
+// ResultFromContext returns the Result of the request stored by the middleware.
+func ResultFromContext(ctx context.Context) (*Result, bool) {
+	r, ok := ctx.Value(resultKey{}).(*Result)
+	return r, ok
+}
+
+// New returns a middleware which limits the requests with the Limiter.
+func New(l Limiter, opts ...Option) func(http.Handler) http.Handler {
+	o := options{
+		keyFunc:       KeyByIP(),
+		deniedHandler: http.HandlerFunc(defaultDeniedHandler),
+		errorHandler:  defaultErrorHandler,
+	}
+	for _, f := range opts {
+		f(&o)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, err := o.keyFunc(r)
+			if err == nil && key == "" {
+				err = ErrEmptyKey
+			}
+			if err != nil {
+				o.errorHandler(w, r, err)
+				return
+			}
+			result, err := l.Take(r.Context(), key)
+			if err != nil {
+				o.errorHandler(w, r, err)
+				return
+			}
+			setHeaders(w.Header(), result)
+			r = r.WithContext(context.WithValue(r.Context(), resultKey{}, result))
+			if !result.Allowed {
+				o.deniedHandler.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func setHeaders(h http.Header, r *Result) {
+	if r.Limit >= 0 {
+		h.Set(HeaderRateLimitLimit, strconv.Itoa(r.Limit))
+	}
+	if r.Remaining >= 0 {
+		h.Set(HeaderRateLimitRemaining, strconv.Itoa(r.Remaining))
+	}
+	if r.ResetAfter >= 0 {
+		h.Set(HeaderRateLimitReset, deltaSeconds(r.ResetAfter))
+	}
+	if !r.Allowed && r.RetryAfter >= 0 {
+		h.Set(HeaderRetryAfter, deltaSeconds(r.RetryAfter))
+	}
+}
+
+// deltaSeconds returns the delay-seconds rounded up.
+func deltaSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
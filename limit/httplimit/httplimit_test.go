@@ -0,0 +1,123 @@
+package httplimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/things-go/limiter/limit"
+	"github.com/things-go/limiter/limit/memory"
+	redisV9 "github.com/things-go/limiter/limit/redis/v9"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+})
+
+func serve(h http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestNew_Period(t *testing.T) {
+	store := memory.NewPeriodStore()
+	defer store.Close()
+
+	l := limit.NewPeriodLimit(store, limit.WithPeriod(time.Minute), limit.WithQuota(2))
+	h := New(Period(l))(okHandler)
+
+	w := serve(h, "10.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "1", w.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "60", w.Header().Get(HeaderRateLimitReset))
+	assert.Empty(t, w.Header().Get(HeaderRetryAfter))
+
+	w = serve(h, "10.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get(HeaderRateLimitRemaining))
+
+	w = serve(h, "10.0.0.1:4321")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "60", w.Header().Get(HeaderRetryAfter))
+
+	// other client
+	w = serve(h, "10.0.0.2:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestNew_Token(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	store := redisV9.NewTokenStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	l := limit.NewKeyedTokenLimit(1, 2, store)
+	defer l.Close()
+	h := New(KeyedToken(l))(okHandler)
+
+	w := serve(h, "10.0.0.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "1", w.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "1", w.Header().Get(HeaderRateLimitReset))
+
+	serve(h, "10.0.0.1:1234")
+	w = serve(h, "10.0.0.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get(HeaderRetryAfter))
+
+	tl := limit.NewTokenLimit(1, 1, "httplimit:token", store)
+	defer tl.Close()
+	h = New(Token(tl))(okHandler)
+	assert.Equal(t, http.StatusOK, serve(h, "10.0.0.1:1234").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(h, "10.0.0.2:1234").Code)
+}
+
+func TestNew_Options(t *testing.T) {
+	denied := LimiterFunc(func(context.Context, string) (*Result, error) {
+		return &Result{Allowed: false, Limit: -1, Remaining: -1, ResetAfter: -1, RetryAfter: -1}, nil
+	})
+	h := New(denied,
+		WithKeyFunc(func(r *http.Request) (string, error) { return r.Header.Get("X-Api-Key"), nil }),
+		WithDeniedHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, ok := ResultFromContext(r.Context())
+			assert.True(t, ok)
+			assert.False(t, result.Allowed)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})),
+	)(okHandler)
+
+	// empty key
+	w := serve(h, "10.0.0.1:1234")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Api-Key", "key")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Empty(t, w.Header().Get(HeaderRateLimitLimit))
+	assert.Empty(t, w.Header().Get(HeaderRetryAfter))
+
+	// fail open
+	failed := LimiterFunc(func(context.Context, string) (*Result, error) {
+		return nil, errors.New("unavailable")
+	})
+	h = New(failed, WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		okHandler.ServeHTTP(w, r)
+	}))(okHandler)
+	assert.Equal(t, http.StatusOK, serve(h, "10.0.0.1:1234").Code)
+}
@@ -0,0 +1,236 @@
+package httplimit
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// client ip headers
+const (
+	HeaderForwarded     = "Forwarded"
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderXRealIP       = "X-Real-IP"
+)
+
+// ClientIPOption client ip option
+type ClientIPOption func(*ClientIP)
+
+// WithTrustedProxies set the trusted proxies, the client ip headers are only honored
+// when the request comes from them, otherwise the remote address is the client ip.
+// such as netip.MustParsePrefix("10.0.0.0/8").
+// default: none, the client ip headers are ignored.
+func WithTrustedProxies(prefixes ...netip.Prefix) ClientIPOption {
+	return func(c *ClientIP) {
+		for _, p := range prefixes {
+			c.trustedProxies = append(c.trustedProxies, p.Masked())
+		}
+	}
+}
+
+// WithClientIPHeader set the client ip header which the trusted proxies set, only this header is honored,
+// such as X-Forwarded-For, Forwarded or X-Real-IP. It must be the one the proxies overwrite or append to,
+// otherwise the client can choose the key by sending the header itself.
+// default: X-Forwarded-For
+func WithClientIPHeader(name string) ClientIPOption {
+	return func(c *ClientIP) {
+		if name != "" {
+			c.header = name
+		}
+	}
+}
+
+// WithIPv4Prefix set the prefix bits the IPv4 addresses grouped by.
+// default: 32
+func WithIPv4Prefix(bits int) ClientIPOption {
+	return func(c *ClientIP) {
+		if bits > 0 && bits <= 32 {
+			c.ipv4Bits = bits
+		}
+	}
+}
+
+// WithIPv6Prefix set the prefix bits the IPv6 addresses grouped by,
+// a client usually owns a whole /64, it can rotate the addresses inside it.
+// default: 64
+func WithIPv6Prefix(bits int) ClientIPOption {
+	return func(c *ClientIP) {
+		if bits > 0 && bits <= 128 {
+			c.ipv6Bits = bits
+		}
+	}
+}
+
+// ClientIP extracts the client ip of the request.
+type ClientIP struct {
+	trustedProxies []netip.Prefix
+	header         string
+	ipv4Bits       int
+	ipv6Bits       int
+}
+
+// NewClientIP returns a ClientIP with given options.
+func NewClientIP(opts ...ClientIPOption) *ClientIP {
+	c := &ClientIP{
+		header:   HeaderXForwardedFor,
+		ipv4Bits: 32,
+		ipv6Bits: 64,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// IP returns the client ip of the request.
+// If the request comes from a trusted proxy, the addresses in the client ip header are walked
+// from right to left, the first untrusted one is the client ip, see WithClientIPHeader.
+func (c *ClientIP) IP(r *http.Request) (netip.Addr, error) {
+	remote, ok := parseAddr(r.RemoteAddr)
+	if !ok {
+		return netip.Addr{}, fmt.Errorf("httplimit: invalid remote address %q", r.RemoteAddr)
+	}
+	if !c.isTrusted(remote) {
+		return remote, nil
+	}
+	ip := remote
+	chain := headerChain(r.Header, c.header)
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr, ok := parseAddr(chain[i])
+		if !ok {
+			// such as "unknown" or an obfuscated identifier, the addresses before it can not be trusted.
+			break
+		}
+		ip = addr
+		if !c.isTrusted(addr) {
+			break
+		}
+	}
+	return ip, nil
+}
+
+// Key returns the client ip grouped by the prefix as the key, such as "192.0.2.1" or "2001:db8:1:2::/64".
+func (c *ClientIP) Key(r *http.Request) (string, error) {
+	ip, err := c.IP(r)
+	if err != nil {
+		return "", err
+	}
+	bits := c.ipv6Bits
+	if ip.Is4() {
+		bits = c.ipv4Bits
+	}
+	if bits == ip.BitLen() {
+		return ip.String(), nil
+	}
+	prefix, err := ip.Prefix(bits)
+	if err != nil {
+		return "", err
+	}
+	return prefix.String(), nil
+}
+
+func (c *ClientIP) isTrusted(ip netip.Addr) bool {
+	for _, p := range c.trustedProxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyByIP returns a KeyFunc which uses the client ip as the key, see ClientIP.
+func KeyByIP(opts ...ClientIPOption) KeyFunc {
+	return NewClientIP(opts...).Key
+}
+
+// KeyByMethod uses the request method as the key.
+func KeyByMethod(r *http.Request) (string, error) {
+	return r.Method, nil
+}
+
+// KeyByPath uses the request url path as the key.
+func KeyByPath(r *http.Request) (string, error) {
+	return r.URL.Path, nil
+}
+
+// KeyByHeader returns a KeyFunc which uses the request header value as the key, such as an api key.
+func KeyByHeader(name string) KeyFunc {
+	return func(r *http.Request) (string, error) {
+		return r.Header.Get(name), nil
+	}
+}
+
+// Composite returns a KeyFunc which joins the keys with "|", such as ip + path, api key + method.
+// It returns ErrEmptyKey if any key is empty.
+func Composite(fns ...KeyFunc) KeyFunc {
+	return func(r *http.Request) (string, error) {
+		keys := make([]string, 0, len(fns))
+		for _, f := range fns {
+			key, err := f(r)
+			if err != nil {
+				return "", err
+			}
+			if key == "" {
+				return "", ErrEmptyKey
+			}
+			keys = append(keys, key)
+		}
+		return strings.Join(keys, "|"), nil
+	}
+}
+
+// headerChain returns the addresses of the client ip header, the client first.
+func headerChain(h http.Header, name string) []string {
+	values := h.Values(name)
+	if len(values) == 0 {
+		return nil
+	}
+	switch http.CanonicalHeaderKey(name) {
+	case HeaderXRealIP:
+		return values[len(values)-1:]
+	case HeaderForwarded:
+		var chain []string
+		for _, v := range values {
+			for _, elem := range strings.Split(v, ",") {
+				chain = append(chain, forwardedFor(elem))
+			}
+		}
+		return chain
+	default:
+		var chain []string
+		for _, v := range values {
+			chain = append(chain, strings.Split(v, ",")...)
+		}
+		return chain
+	}
+}
+
+// forwardedFor returns the "for" parameter of a Forwarded element, RFC 7239.
+func forwardedFor(elem string) string {
+	for _, pair := range strings.Split(elem, ";") {
+		k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && strings.EqualFold(k, "for") {
+			return strings.Trim(v, `"`)
+		}
+	}
+	return ""
+}
+
+// parseAddr parses an ip address with optional port, such as "192.0.2.1", "192.0.2.1:80",
+// "2001:db8::1" or "[2001:db8::1]:80".
+func parseAddr(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return addr.Unmap(), true
+	}
+	if addrPort, err := netip.ParseAddrPort(s); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		if addr, err := netip.ParseAddr(s[1 : len(s)-1]); err == nil {
+			return addr.Unmap(), true
+		}
+	}
+	return netip.Addr{}, false
+}
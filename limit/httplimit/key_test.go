@@ -0,0 +1,148 @@
+package httplimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequest(remoteAddr string, header map[string][]string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/export", nil)
+	r.RemoteAddr = remoteAddr
+	for k, vs := range header {
+		for _, v := range vs {
+			r.Header.Add(k, v)
+		}
+	}
+	return r
+}
+
+func TestClientIP_IP(t *testing.T) {
+	trustedProxies := WithTrustedProxies(
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8:ffff::/48"),
+	)
+	tests := []struct {
+		name           string
+		clientIPHeader string
+		remoteAddr     string
+		header         map[string][]string
+		want           string
+	}{
+		{
+			name:       "untrusted remote ignores headers",
+			remoteAddr: "198.51.100.1:1234",
+			header:     map[string][]string{HeaderXForwardedFor: {"203.0.113.1"}},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "trusted remote without headers",
+			remoteAddr: "10.0.0.1:1234",
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "x-forwarded-for skips trusted proxies from right",
+			remoteAddr: "10.0.0.1:1234",
+			header:     map[string][]string{HeaderXForwardedFor: {"1.1.1.1, 203.0.113.1", "10.0.0.2"}},
+			want:       "203.0.113.1",
+		},
+		{
+			name:       "x-forwarded-for all trusted",
+			remoteAddr: "10.0.0.1:1234",
+			header:     map[string][]string{HeaderXForwardedFor: {"10.0.0.3, 10.0.0.2"}},
+			want:       "10.0.0.3",
+		},
+		{
+			name:       "x-forwarded-for invalid entry stops the walk",
+			remoteAddr: "10.0.0.1:1234",
+			header:     map[string][]string{HeaderXForwardedFor: {"203.0.113.1, unknown, 10.0.0.2"}},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "other headers are ignored",
+			remoteAddr: "10.0.0.1:1234",
+			header: map[string][]string{
+				HeaderForwarded: {"for=203.0.113.1"},
+				HeaderXRealIP:   {"203.0.113.2"},
+			},
+			want: "10.0.0.1",
+		},
+		{
+			name:           "forwarded",
+			clientIPHeader: HeaderForwarded,
+			remoteAddr:     "[2001:db8:ffff::1]:443",
+			header: map[string][]string{
+				HeaderForwarded: {`for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711";by=10.0.0.1`},
+			},
+			want: "2001:db8:cafe::17",
+		},
+		{
+			name:           "x-real-ip ignores the x-forwarded-for from the client",
+			clientIPHeader: HeaderXRealIP,
+			remoteAddr:     "10.0.0.1:1234",
+			header: map[string][]string{
+				HeaderXRealIP:       {"203.0.113.9"},
+				HeaderXForwardedFor: {"192.0.2.77"},
+			},
+			want: "203.0.113.9",
+		},
+		{
+			name:       "ipv4 mapped ipv6",
+			remoteAddr: "[::ffff:198.51.100.1]:1234",
+			want:       "198.51.100.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClientIP(trustedProxies, WithClientIPHeader(tt.clientIPHeader))
+			ip, err := c.IP(newRequest(tt.remoteAddr, tt.header))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ip.String())
+		})
+	}
+
+	_, err := NewClientIP(trustedProxies).IP(newRequest("invalid", nil))
+	assert.Error(t, err)
+}
+
+func TestClientIP_Key(t *testing.T) {
+	key, err := KeyByIP()(newRequest("[2001:db8:1:2:aaaa::1]:1234", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8:1:2::/64", key)
+
+	// rotating addresses inside one /64 shares the key.
+	key2, err := KeyByIP()(newRequest("[2001:db8:1:2:bbbb::2]:1234", nil))
+	require.NoError(t, err)
+	assert.Equal(t, key, key2)
+
+	key, err = KeyByIP(WithIPv6Prefix(48), WithIPv4Prefix(24))(newRequest("[2001:db8:1:2::1]:1234", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "2001:db8:1::/48", key)
+
+	key, err = KeyByIP(WithIPv4Prefix(24))(newRequest("198.51.100.7:1234", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.0/24", key)
+
+	key, err = KeyByIP()(newRequest("198.51.100.7:1234", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.7", key)
+}
+
+func TestComposite(t *testing.T) {
+	r := newRequest("198.51.100.7:1234", map[string][]string{"X-Api-Key": {"secret"}})
+
+	key, err := Composite(KeyByIP(), KeyByPath)(r)
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.7|/export", key)
+
+	key, err = Composite(KeyByHeader("X-Api-Key"), KeyByMethod)(r)
+	require.NoError(t, err)
+	assert.Equal(t, "secret|GET", key)
+
+	_, err = Composite(KeyByHeader("X-Missing"), KeyByMethod)(r)
+	assert.ErrorIs(t, err, ErrEmptyKey)
+}
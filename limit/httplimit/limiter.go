@@ -0,0 +1,100 @@
+package httplimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/things-go/limiter/limit"
+)
+
+// Result the decision of a Limiter.
+type Result struct {
+	// Allowed reports whether the request is allowed.
+	Allowed bool
+	// Limit the quota of the limiter, -1 if unknown.
+	Limit int
+	// Remaining the remaining quota, -1 if unknown.
+	Remaining int
+	// ResetAfter the time until the quota resets, -1 if unknown.
+	ResetAfter time.Duration
+	// RetryAfter the time until the request will be allowed if denied, -1 if unknown or never.
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether the request of the key is allowed.
+type Limiter interface {
+	Take(ctx context.Context, key string) (*Result, error)
+}
+
+// LimiterFunc is an adapter to allow the use of ordinary functions as Limiter.
+type LimiterFunc func(ctx context.Context, key string) (*Result, error)
+
+// Take calls f(ctx, key).
+func (f LimiterFunc) Take(ctx context.Context, key string) (*Result, error) {
+	return f(ctx, key)
+}
+
+// Period returns a Limiter of the PeriodLimitDriver, the request is denied if over quota.
+func Period(d limit.PeriodLimitDriver) Limiter {
+	return LimiterFunc(func(ctx context.Context, key string) (*Result, error) {
+		r, err := d.TakeWithResult(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{
+			Allowed:    !r.State.IsOverQuota(),
+			Limit:      r.Limit,
+			Remaining:  r.Remaining,
+			ResetAfter: r.ResetAfter,
+			RetryAfter: r.RetryAfter,
+		}, nil
+	})
+}
+
+// GCRA returns a Limiter of the GCRALimit.
+func GCRA[S limit.GCRAStorage](l *limit.GCRALimit[S]) Limiter {
+	return LimiterFunc(func(ctx context.Context, key string) (*Result, error) {
+		r, err := l.Allow(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{
+			Allowed:    r.Allowed,
+			Limit:      r.Limit,
+			Remaining:  r.Remaining,
+			ResetAfter: r.ResetAfter,
+			RetryAfter: r.RetryAfter,
+		}, nil
+	})
+}
+
+// Token returns a Limiter of the TokenLimit, the key is ignored as the TokenLimit has a fixed key,
+// use KeyedToken to limit per key.
+func Token[S limit.TokenStorage](l *limit.TokenLimit[S]) Limiter {
+	return LimiterFunc(func(ctx context.Context, _ string) (*Result, error) {
+		return tokenResult(l.TryReserveN(ctx, time.Now(), 1), l.Limit(), l.Burst()), nil
+	})
+}
+
+// KeyedToken returns a Limiter of the KeyedTokenLimit.
+func KeyedToken[S limit.TokenStorage](l *limit.KeyedTokenLimit[S]) Limiter {
+	return LimiterFunc(func(ctx context.Context, key string) (*Result, error) {
+		return tokenResult(l.TryReserveN(ctx, key, time.Now(), 1), l.Limit(), l.Burst()), nil
+	})
+}
+
+// tokenResult the quota of a token bucket is the burst, it resets when the bucket is full.
+func tokenResult(r *limit.TokenReservation, rate float64, burst int) *Result {
+	remaining := r.Remaining()
+	resetAfter := time.Duration(-1)
+	if remaining >= 0 && rate > 0 {
+		resetAfter = time.Duration(float64(burst-remaining) / rate * float64(time.Second))
+	}
+	return &Result{
+		Allowed:    r.OK(),
+		Limit:      burst,
+		Remaining:  remaining,
+		ResetAfter: resetAfter,
+		RetryAfter: r.RetryAfter(),
+	}
+}
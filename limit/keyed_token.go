@@ -0,0 +1,88 @@
+package limit
+
+import (
+	"context"
+	"time"
+
+	xrate "golang.org/x/time/rate"
+)
+
+// KeyedTokenLimit controls how frequently events are allowed to happen with in one second per key,
+// one instance serves many keys, such as per user or per ip.
+// if the storage is unavailable, it follows the failure policy, default uses per-key in-process
+// limiters for rescue, which kept in a bounded LRU cache.
+type KeyedTokenLimit[S TokenStorage] struct {
+	rate           float64
+	burst          int
+	store          S
+	rescueLimiters *lru[string, *xrate.Limiter]
+	opt            *tokenLimitOption
+	*storeHealth
+}
+
+// NewKeyedTokenLimit returns a new KeyedTokenLimit that allows events up to rate per second and permits
+// bursts of at most burst tokens per key, rate may be fractional, see Every.
+func NewKeyedTokenLimit[S TokenStorage](rate float64, burst int, store S, opts ...TokenLimitOption) *KeyedTokenLimit[S] {
+	o := newTokenLimitOption(opts...)
+	return &KeyedTokenLimit[S]{
+		rate:           rate,
+		burst:          burst,
+		store:          store,
+		rescueLimiters: newLRU[string, *xrate.Limiter](o.rescueCacheSize),
+		opt:            o,
+		storeHealth:    newStoreHealth(store, o),
+	}
+}
+
+// Close releases the HealthChecker of the storage, the shared one will be closed
+// when all limiters of the storage closed.
+func (t *KeyedTokenLimit[S]) Close() {
+	t.storeHealth.close()
+}
+
+// Allow is shorthand for AllowN(ctx, key, time.Now(), 1).
+func (t *KeyedTokenLimit[S]) Allow(ctx context.Context, key string) bool {
+	return t.AllowN(ctx, key, time.Now(), 1)
+}
+
+// AllowN reports whether n events may happen at time now for the key.
+func (t *KeyedTokenLimit[S]) AllowN(ctx context.Context, key string, now time.Time, n int) bool {
+	return t.TryReserveN(ctx, key, now, n).OK()
+}
+
+// TryReserveN reserves n tokens for the key only if they are available at time now, same as AllowN,
+// but the returned TokenReservation reports the remaining tokens, or the time to retry if not OK.
+func (t *KeyedTokenLimit[S]) TryReserveN(ctx context.Context, key string, now time.Time, n int) *TokenReservation {
+	if !t.isAlive() {
+		return t.fallback(ctx, key, now, n, ErrStoreUnavailable)
+	}
+
+	tb, err := t.store.Take(ctx, key, t.rate, t.burst, now, n, 0)
+	if err == nil && len(tb) != 3 {
+		err = ErrUnknownCode
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return newUnknownTokenReservation(false, time.Time{})
+		}
+		t.startMonitor(err)
+		return t.fallback(ctx, key, now, n, err)
+	}
+	return newTokenReservation(now, n, t.burst, tb)
+}
+
+// Limit returns the maximum overall event rate per key.
+func (t *KeyedTokenLimit[S]) Limit() float64 { return t.rate }
+
+// Burst returns the maximum burst size per key.
+func (t *KeyedTokenLimit[S]) Burst() int { return t.burst }
+
+func (t *KeyedTokenLimit[S]) fallback(ctx context.Context, key string, now time.Time, n int, err error) *TokenReservation {
+	var rescue *xrate.Limiter
+	if t.opt.failurePolicy == FailurePolicyLocalFallback {
+		rescue = t.rescueLimiters.GetOrAdd(key, func() *xrate.Limiter {
+			return t.opt.newRescueLimiter(t.rate, t.burst)
+		})
+	}
+	return t.opt.fallback(ctx, key, now, n, 0, err, rescue)
+}
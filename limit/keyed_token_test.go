@@ -0,0 +1,40 @@
+package limit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyedTokenLimit_Rescue(t *testing.T) {
+	const (
+		rate  = 5
+		burst = 10
+	)
+	store := &fakeTokenStore{}
+	l := NewKeyedTokenLimit(rate, burst, store, WithRescueCacheSize(2))
+
+	var first, second int
+	for i := 0; i < burst*2; i++ {
+		if l.Allow(context.Background(), "first") {
+			first++
+		}
+		if l.Allow(context.Background(), "second") {
+			second++
+		}
+	}
+	assert.Equal(t, burst, first)
+	assert.Equal(t, burst, second)
+
+	// evict the least recently used rescue limiter of the first key.
+	l.Allow(context.Background(), "third")
+	assert.Equal(t, 2, l.rescueLimiters.Len())
+	_, ok := l.rescueLimiters.Get("first")
+	assert.False(t, ok)
+
+	store.alive.Store(true)
+	assert.Eventually(t, l.isAlive, time.Second, TokenLimitPingInterval)
+	assert.True(t, l.Allow(context.Background(), "first"))
+}
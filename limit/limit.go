@@ -1,44 +1,235 @@
 package limit
 
 import (
+	"container/list"
+	"context"
 	"errors"
+	"sync"
 )
 
 // ErrLimitReturn indicates that the more than borrowed elements were returned.
 var ErrLimitReturn = errors.New("limit: discarding limited token, resource pool is full, someone returned multiple times")
 
-// Limit controls the concurrent requests.
+// Limit controls the concurrent requests, it is a weighted semaphore with FIFO fairness,
+// the waiters are served in order, so large requests are not starved.
+// The capacity can be changed at runtime, see SetCap.
+// The copies of a Limit share the same state.
+// The zero value is a Limit without capacity, like a nil channel, it never lends any element,
+// use NewLimit to create a usable one.
 type Limit struct {
-	pool chan struct{}
+	s *semaphore
+}
+
+type semaphore struct {
+	mu      sync.Mutex
+	size    int
+	cur     int
+	waiters list.List
+}
+
+type waiter struct {
+	n     int
+	ready chan<- struct{} // Closed when semaphore acquired.
 }
 
 // NewLimit creates a Limit that can borrow n elements from it concurrently.
+// It panics if n is negative.
 func NewLimit(n int) Limit {
-	return Limit{make(chan struct{}, n)}
+	mustNonNegative(n)
+	return Limit{&semaphore{size: n}}
 }
 
 // Borrow borrows an element from Limit in blocking mode.
 func (l Limit) Borrow() {
-	l.pool <- struct{}{}
+	_ = l.BorrowN(context.Background(), 1)
 }
 
-// Return returns the borrowed resource, returns error only if returned more than borrowed.
-func (l Limit) Return() error {
+// BorrowCtx borrows an element from Limit, blocking until success or ctx is done.
+// On failure, returns ctx.Err() and leaves the Limit unchanged.
+func (l Limit) BorrowCtx(ctx context.Context) error {
+	return l.BorrowN(ctx, 1)
+}
+
+// BorrowN borrows n elements from Limit, blocking until success or ctx is done.
+// On failure, returns ctx.Err() and leaves the Limit unchanged.
+// NOTE: if n is greater than the capacity, it blocks until the capacity grows or ctx is done,
+// and does not block the other waiters. It panics if n is negative.
+func (l Limit) BorrowN(ctx context.Context, n int) error {
+	mustNonNegative(n)
+	s := l.s
+	done := ctx.Done()
+	if s == nil {
+		// the zero value never lends.
+		<-done
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
 	select {
-	case <-l.pool:
-		return nil
+	case <-done:
+		// ctx becoming done has "happened before" borrowing, return ctx.Err() even if
+		// we could borrow without blocking.
+		s.mu.Unlock()
+		return ctx.Err()
 	default:
-		return ErrLimitReturn
+	}
+	if s.size-s.cur >= n && s.frontEligible() == nil {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	elem := s.waiters.PushBack(waiter{n: n, ready: ready})
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+		s.mu.Lock()
+		select {
+		case <-ready:
+			// borrowed after canceled, pretend we didn't and return the elements back.
+			s.cur -= n
+			s.notifyWaiters()
+		default:
+			s.waiters.Remove(elem)
+			// the waiters behind us may be served now.
+			s.notifyWaiters()
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	case <-ready:
+		return nil
 	}
 }
 
 // TryBorrow tries to borrow an element from Limit, in non-blocking mode.
 // If success, true returned, false for otherwise.
 func (l Limit) TryBorrow() bool {
-	select {
-	case l.pool <- struct{}{}:
-		return true
-	default:
+	return l.TryBorrowN(1)
+}
+
+// TryBorrowN tries to borrow n elements from Limit, in non-blocking mode.
+// If success, true returned, false for otherwise. It panics if n is negative.
+func (l Limit) TryBorrowN(n int) bool {
+	mustNonNegative(n)
+	s := l.s
+	if s == nil {
 		return false
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.cur >= n && s.frontEligible() == nil {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// Return returns the borrowed resource, returns error only if returned more than borrowed.
+func (l Limit) Return() error {
+	return l.ReturnN(1)
+}
+
+// ReturnN returns n borrowed elements, returns error only if returned more than borrowed.
+// It panics if n is negative.
+func (l Limit) ReturnN(n int) error {
+	mustNonNegative(n)
+	s := l.s
+	if s == nil {
+		return ErrLimitReturn
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cur < n {
+		return ErrLimitReturn
+	}
+	s.cur -= n
+	s.notifyWaiters()
+	return nil
+}
+
+// SetCap changes the capacity of Limit at runtime.
+// Shrinking does not revoke the borrowed elements, the borrowers wait until the in-use
+// drops below the new capacity; growing wakes the waiters.
+// It panics if n is negative or the Limit is the zero value.
+func (l Limit) SetCap(n int) {
+	mustNonNegative(n)
+	s := l.s
+	if s == nil {
+		panic("limit: SetCap on the zero value Limit, use NewLimit")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.size = n
+	s.notifyWaiters()
+}
+
+// Cap returns the capacity of Limit.
+func (l Limit) Cap() int {
+	if l.s == nil {
+		return 0
+	}
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+	return l.s.size
+}
+
+// InUse returns the number of borrowed elements.
+func (l Limit) InUse() int {
+	if l.s == nil {
+		return 0
+	}
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+	return l.s.cur
+}
+
+// Waiting returns the number of waiters.
+func (l Limit) Waiting() int {
+	if l.s == nil {
+		return 0
+	}
+	l.s.mu.Lock()
+	defer l.s.mu.Unlock()
+	return l.s.waiters.Len()
+}
+
+// mustNonNegative panics if n is negative, which would change the capacity silently.
+func mustNonNegative(n int) {
+	if n < 0 {
+		panic("limit: negative n")
+	}
+}
+
+// frontEligible returns the first waiter which can be satisfied by the capacity.
+// NOTE: the caller must hold the lock.
+func (s *semaphore) frontEligible() *list.Element {
+	for e := s.waiters.Front(); e != nil; e = e.Next() {
+		if e.Value.(waiter).n <= s.size {
+			return e
+		}
+	}
+	return nil
+}
+
+// notifyWaiters NOTE: the caller must hold the lock.
+func (s *semaphore) notifyWaiters() {
+	for {
+		next := s.frontEligible()
+		if next == nil {
+			break // No more waiters can be served.
+		}
+
+		w := next.Value.(waiter)
+		if s.size-s.cur < w.n {
+			// Not enough elements for the next waiter, keep FIFO, so that
+			// a large request is not starved by the small ones.
+			break
+		}
+
+		s.cur += w.n
+		s.waiters.Remove(next)
+		close(w.ready)
+	}
 }
@@ -1,9 +1,12 @@
 package limit
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLimit(t *testing.T) {
@@ -15,3 +18,144 @@ func TestLimit(t *testing.T) {
 	assert.Nil(t, limit.Return())
 	assert.Equal(t, ErrLimitReturn, limit.Return())
 }
+
+func TestLimit_ZeroValue(t *testing.T) {
+	var limit Limit
+	assert.False(t, limit.TryBorrow())
+	assert.Equal(t, ErrLimitReturn, limit.Return())
+	assert.Zero(t, limit.Cap())
+	assert.Zero(t, limit.InUse())
+	assert.Zero(t, limit.Waiting())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limit.BorrowCtx(ctx), context.DeadlineExceeded)
+	assert.Panics(t, func() { limit.SetCap(1) })
+}
+
+func TestLimit_NegativeN(t *testing.T) {
+	limit := NewLimit(2)
+	assert.Panics(t, func() { NewLimit(-1) })
+	assert.Panics(t, func() { _ = limit.BorrowN(context.Background(), -1) })
+	assert.Panics(t, func() { limit.TryBorrowN(-1) })
+	assert.Panics(t, func() { _ = limit.ReturnN(-1) })
+	assert.Panics(t, func() { limit.SetCap(-1) })
+	assert.Equal(t, 2, limit.Cap())
+	assert.Zero(t, limit.InUse())
+}
+
+func TestLimit_BorrowCtx(t *testing.T) {
+	limit := NewLimit(1)
+	require.NoError(t, limit.BorrowCtx(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := limit.BorrowCtx(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, limit.InUse())
+	assert.Zero(t, limit.Waiting())
+
+	// canceled before borrow
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, limit.Return())
+	assert.ErrorIs(t, limit.BorrowCtx(ctx), context.Canceled)
+	assert.Zero(t, limit.InUse())
+}
+
+func TestLimit_BorrowN(t *testing.T) {
+	limit := NewLimit(3)
+	assert.Equal(t, 3, limit.Cap())
+	require.NoError(t, limit.BorrowN(context.Background(), 2))
+	assert.Equal(t, 2, limit.InUse())
+	assert.False(t, limit.TryBorrowN(2))
+
+	// more than the capacity, blocks until ctx done.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limit.BorrowN(ctx, 4), context.DeadlineExceeded)
+
+	assert.Equal(t, ErrLimitReturn, limit.ReturnN(3))
+	require.NoError(t, limit.ReturnN(2))
+	assert.Zero(t, limit.InUse())
+}
+
+func TestLimit_FIFO(t *testing.T) {
+	limit := NewLimit(3)
+	require.NoError(t, limit.BorrowN(context.Background(), 2))
+
+	// the large request waits in front.
+	large := make(chan struct{})
+	go func() {
+		defer close(large)
+		assert.NoError(t, limit.BorrowN(context.Background(), 3))
+	}()
+	require.Eventually(t, func() bool { return limit.Waiting() == 1 }, time.Second, time.Millisecond)
+
+	// the small one is not allowed to jump the queue.
+	assert.False(t, limit.TryBorrow())
+	small := make(chan struct{})
+	go func() {
+		defer close(small)
+		assert.NoError(t, limit.BorrowCtx(context.Background()))
+	}()
+	require.Eventually(t, func() bool { return limit.Waiting() == 2 }, time.Second, time.Millisecond)
+
+	require.NoError(t, limit.ReturnN(2))
+	<-large
+	assert.Equal(t, 3, limit.InUse())
+	assert.Equal(t, 1, limit.Waiting())
+
+	require.NoError(t, limit.ReturnN(3))
+	<-small
+	assert.Equal(t, 1, limit.InUse())
+	assert.Zero(t, limit.Waiting())
+}
+
+func TestLimit_CancelFrontWaiter(t *testing.T) {
+	limit := NewLimit(3)
+	require.NoError(t, limit.BorrowN(context.Background(), 2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	large := make(chan error)
+	go func() { large <- limit.BorrowN(ctx, 3) }()
+	require.Eventually(t, func() bool { return limit.Waiting() == 1 }, time.Second, time.Millisecond)
+
+	small := make(chan error)
+	go func() { small <- limit.BorrowCtx(context.Background()) }()
+	require.Eventually(t, func() bool { return limit.Waiting() == 2 }, time.Second, time.Millisecond)
+
+	// cancel the front waiter, the next one can be served.
+	cancel()
+	assert.ErrorIs(t, <-large, context.Canceled)
+	assert.NoError(t, <-small)
+	assert.Equal(t, 3, limit.InUse())
+}
+
+func TestLimit_SetCap(t *testing.T) {
+	limit := NewLimit(2)
+	require.NoError(t, limit.BorrowN(context.Background(), 2))
+
+	// shrink, the in-flight holders are respected.
+	limit.SetCap(1)
+	assert.Equal(t, 1, limit.Cap())
+	assert.Equal(t, 2, limit.InUse())
+	require.NoError(t, limit.Return())
+	assert.False(t, limit.TryBorrow())
+	require.NoError(t, limit.Return())
+	assert.True(t, limit.TryBorrow())
+
+	// the waiter larger than the capacity does not block the others.
+	large := make(chan error)
+	go func() { large <- limit.BorrowN(context.Background(), 3) }()
+	require.Eventually(t, func() bool { return limit.Waiting() == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, limit.Return())
+	assert.True(t, limit.TryBorrow())
+	require.NoError(t, limit.Return())
+
+	// grow, wake the waiter.
+	limit.SetCap(3)
+	assert.NoError(t, <-large)
+	assert.Equal(t, 3, limit.InUse())
+	assert.Zero(t, limit.Waiting())
+}
@@ -0,0 +1,264 @@
+// Package limitconfig builds and registers the limiters of the PeriodLimitManager and
+// PeriodFailureLimitManager from a declarative YAML or JSON configuration, and optionally
+// watches the file by polling, so the limits can be changed without a deploy.
+package limitconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/things-go/limiter/limit"
+)
+
+// ErrEmptyConfig is an error that the configuration is empty or defines no limiter.
+var ErrEmptyConfig = errors.New("limitconfig: empty configuration, no limiter defined")
+
+// Algorithm the algorithm of the period limit.
+type Algorithm string
+
+// algorithms
+const (
+	// AlgorithmFixedWindow fixed window, the default algorithm.
+	AlgorithmFixedWindow Algorithm = "fixed_window"
+	// AlgorithmSlidingLog sliding window log.
+	AlgorithmSlidingLog Algorithm = "sliding_log"
+	// AlgorithmSlidingWindow sliding window counter.
+	AlgorithmSlidingWindow Algorithm = "sliding_window"
+)
+
+// Calendar the calendar boundary which the window is aligned with.
+type Calendar string
+
+// calendars
+const (
+	CalendarHour  Calendar = "hour"
+	CalendarDay   Calendar = "day"
+	CalendarWeek  Calendar = "week" // ISO 8601 week, starts on Monday
+	CalendarMonth Calendar = "month"
+)
+
+var calendarUnits = map[Calendar]limit.CalendarUnit{
+	CalendarHour:  limit.CalendarHour,
+	CalendarDay:   limit.CalendarDay,
+	CalendarWeek:  limit.CalendarWeek,
+	CalendarMonth: limit.CalendarMonth,
+}
+
+// Format the format of the configuration file.
+type Format string
+
+// formats
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// FormatFromPath returns the format of the file by its extension.
+func FormatFromPath(path string) (Format, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("limitconfig: unsupported file extension %q of %s, want .yaml, .yml or .json", ext, path)
+	}
+}
+
+// Duration a time.Duration, which is written as a string such as "1s", "1h30m".
+type Duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Config the declarative configuration of the limiters.
+//
+//	period_limits:
+//	  - name: sms
+//	    algorithm: fixed_window
+//	    quota: 5
+//	    period: 24h
+//	    align: true
+//	    key_prefix: "limit:sms:"
+//	    store: redis
+//	  - name: export
+//	    quota: 5
+//	    calendar: month
+//	    location: Asia/Shanghai
+//	    store: redis
+//	period_failure_limits:
+//	  - name: password
+//	    quota: 5
+//	    period: 1h
+//	    store: redis
+type Config struct {
+	// PeriodLimits the limiters of the PeriodLimitManager.
+	PeriodLimits []PeriodLimitConfig `json:"period_limits" yaml:"period_limits"`
+	// PeriodFailureLimits the limiters of the PeriodFailureLimitManager.
+	PeriodFailureLimits []PeriodLimitConfig `json:"period_failure_limits" yaml:"period_failure_limits"`
+}
+
+// PeriodLimitConfig the configuration of a named period limiter.
+type PeriodLimitConfig struct {
+	// Name the kind of the driver registered in the manager, must be unique.
+	Name string `json:"name" yaml:"name"`
+	// Algorithm the algorithm, default AlgorithmFixedWindow.
+	// period failure limit only supports AlgorithmFixedWindow.
+	Algorithm Algorithm `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	// Quota limit quota requests during a period of time, must be positive.
+	Quota int `json:"quota" yaml:"quota"`
+	// Period a period of time, must be a multiple of a second, not allowed with Calendar.
+	Period Duration `json:"period,omitempty" yaml:"period,omitempty"`
+	// Align align with the local timezone and the start of the period, not allowed with Calendar.
+	Align bool `json:"align,omitempty" yaml:"align,omitempty"`
+	// Calendar align the window with the calendar boundary in the Location, instead of the Period.
+	Calendar Calendar `json:"calendar,omitempty" yaml:"calendar,omitempty"`
+	// Location the IANA time zone name of the Calendar, such as "Asia/Shanghai", "UTC" or "Local",
+	// required with Calendar.
+	Location string `json:"location,omitempty" yaml:"location,omitempty"`
+	// KeyPrefix the key prefix, default "limit:period:<name>:" or "limit:period:failure:<name>:".
+	KeyPrefix string `json:"key_prefix,omitempty" yaml:"key_prefix,omitempty"`
+	// Store the name of the Store, see WithStore.
+	Store string `json:"store" yaml:"store"`
+}
+
+// Parse parses the configuration, the unknown fields are rejected.
+func Parse(data []byte, format Format) (*Config, error) {
+	c := &Config{}
+	switch format {
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(c); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, ErrEmptyConfig
+			}
+			return nil, fmt.Errorf("limitconfig: parse yaml: %w", err)
+		}
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(c); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, ErrEmptyConfig
+			}
+			return nil, fmt.Errorf("limitconfig: parse json: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("limitconfig: unsupported format %q", format)
+	}
+	return c, nil
+}
+
+// Validate validates the configuration, it returns all the errors joined.
+// a configuration without any limiter is rejected, it may be read while the file is being written.
+func (c *Config) Validate() error {
+	if len(c.PeriodLimits) == 0 && len(c.PeriodFailureLimits) == 0 {
+		return ErrEmptyConfig
+	}
+	var errs []error
+	errs = validatePeriodLimits(errs, "period_limits", c.PeriodLimits, false)
+	errs = validatePeriodLimits(errs, "period_failure_limits", c.PeriodFailureLimits, true)
+	return errors.Join(errs...)
+}
+
+func validatePeriodLimits(errs []error, section string, limits []PeriodLimitConfig, failure bool) []error {
+	names := make(map[string]int, len(limits))
+	for i, v := range limits {
+		invalid := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("limitconfig: %s[%d] %q: %s", section, i, v.Name, fmt.Sprintf(format, args...)))
+		}
+		if v.Name == "" {
+			invalid("name is required")
+		} else if j, ok := names[v.Name]; ok {
+			invalid("duplicate name, already defined in %s[%d]", section, j)
+		} else {
+			names[v.Name] = i
+		}
+		switch v.Algorithm {
+		case "", AlgorithmFixedWindow:
+		case AlgorithmSlidingLog, AlgorithmSlidingWindow:
+			if failure {
+				invalid("algorithm %q is not supported, want %q", v.Algorithm, AlgorithmFixedWindow)
+			}
+			// the sliding stores derive the window from the expire seconds,
+			// which changes on every call if aligned.
+			if v.Align {
+				invalid("align is not allowed with algorithm %q", v.Algorithm)
+			}
+			if v.Calendar != "" {
+				invalid("calendar is not allowed with algorithm %q", v.Algorithm)
+			}
+		default:
+			invalid("unknown algorithm %q, want one of %q, %q, %q",
+				v.Algorithm, AlgorithmFixedWindow, AlgorithmSlidingLog, AlgorithmSlidingWindow)
+		}
+		if v.Quota <= 0 {
+			invalid("quota must be positive, got %d", v.Quota)
+		}
+		if v.Calendar == "" {
+			if d := time.Duration(v.Period); d < time.Second || d%time.Second != 0 {
+				invalid("period must be a positive multiple of a second, got %s", d)
+			}
+			if v.Location != "" {
+				invalid("location requires calendar")
+			}
+		} else {
+			if _, ok := calendarUnits[v.Calendar]; !ok {
+				invalid("unknown calendar %q, want one of %q, %q, %q, %q",
+					v.Calendar, CalendarHour, CalendarDay, CalendarWeek, CalendarMonth)
+			}
+			if v.Period != 0 {
+				invalid("period is not allowed with calendar")
+			}
+			if v.Align {
+				invalid("align is not allowed with calendar")
+			}
+			if v.Location == "" {
+				invalid("location is required with calendar, such as \"Asia/Shanghai\"")
+			} else if _, err := time.LoadLocation(v.Location); err != nil {
+				invalid("invalid location %q: %v", v.Location, err)
+			}
+		}
+		if v.Store == "" {
+			invalid("store is required")
+		}
+	}
+	return errs
+}
+
+// algorithm returns the algorithm, default AlgorithmFixedWindow.
+func (c *PeriodLimitConfig) algorithm() Algorithm {
+	if c.Algorithm == "" {
+		return AlgorithmFixedWindow
+	}
+	return c.Algorithm
+}
+
+// keyPrefix returns the key prefix, default defaultPrefix + name + ":".
+func (c *PeriodLimitConfig) keyPrefix(defaultPrefix string) string {
+	if c.KeyPrefix == "" {
+		return defaultPrefix + c.Name + ":"
+	}
+	return c.KeyPrefix
+}
@@ -0,0 +1,153 @@
+package limitconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	want := &Config{
+		PeriodLimits: []PeriodLimitConfig{
+			{
+				Name:      "sms",
+				Algorithm: AlgorithmSlidingWindow,
+				Quota:     5,
+				Period:    Duration(24 * time.Hour),
+				KeyPrefix: "limit:sms:",
+				Store:     "redis",
+			},
+		},
+		PeriodFailureLimits: []PeriodLimitConfig{
+			{Name: "password", Quota: 3, Period: Duration(time.Hour), Align: true, Store: "redis"},
+		},
+	}
+
+	c, err := Parse([]byte(`
+period_limits:
+  - name: sms
+    algorithm: sliding_window
+    quota: 5
+    period: 24h
+    key_prefix: "limit:sms:"
+    store: redis
+period_failure_limits:
+  - name: password
+    quota: 3
+    period: 1h
+    align: true
+    store: redis
+`), FormatYAML)
+	require.NoError(t, err)
+	assert.Equal(t, want, c)
+
+	c, err = Parse([]byte(`{
+  "period_limits": [
+    {"name": "sms", "algorithm": "sliding_window", "quota": 5, "period": "24h", "key_prefix": "limit:sms:", "store": "redis"}
+  ],
+  "period_failure_limits": [
+    {"name": "password", "quota": 3, "period": "1h", "align": true, "store": "redis"}
+  ]
+}`), FormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, want, c)
+
+	for _, data := range []string{"", "  \n", "# truncated\n"} {
+		_, err = Parse([]byte(data), FormatYAML)
+		assert.ErrorIs(t, err, ErrEmptyConfig)
+	}
+	for _, data := range []string{"", "  \n"} {
+		_, err = Parse([]byte(data), FormatJSON)
+		assert.ErrorIs(t, err, ErrEmptyConfig)
+	}
+	c, err = Parse([]byte("period_limits: []\n"), FormatYAML)
+	require.NoError(t, err)
+	assert.ErrorIs(t, c.Validate(), ErrEmptyConfig)
+
+	_, err = Parse([]byte("period_limits:\n  - name: sms\n    quotas: 5\n"), FormatYAML)
+	assert.ErrorContains(t, err, "field quotas not found")
+	_, err = Parse([]byte(`{"period_limits": [{"name": "sms", "period": "1 day"}]}`), FormatJSON)
+	assert.ErrorContains(t, err, `unknown unit " day"`)
+	_, err = Parse(nil, "toml")
+	assert.Error(t, err)
+}
+
+func TestFormatFromPath(t *testing.T) {
+	for path, want := range map[string]Format{
+		"limit.yaml":     FormatYAML,
+		"/etc/limit.YML": FormatYAML,
+		"limit.json":     FormatJSON,
+	} {
+		f, err := FormatFromPath(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, f)
+	}
+	_, err := FormatFromPath("limit.toml")
+	assert.ErrorContains(t, err, `unsupported file extension ".toml"`)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := PeriodLimitConfig{Name: "sms", Quota: 5, Period: Duration(time.Minute), Store: "redis"}
+	assert.NoError(t, (&Config{PeriodLimits: []PeriodLimitConfig{valid}}).Validate())
+
+	c := &Config{
+		PeriodLimits: []PeriodLimitConfig{
+			valid,
+			valid,
+			{Name: "", Algorithm: "token", Quota: 0, Period: Duration(1500 * time.Millisecond)},
+		},
+		PeriodFailureLimits: []PeriodLimitConfig{
+			{Name: "password", Algorithm: AlgorithmSlidingLog, Quota: 3, Period: Duration(time.Hour), Store: "redis"},
+		},
+	}
+	err := c.Validate()
+	require.Error(t, err)
+	assert.Equal(t, `limitconfig: period_limits[1] "sms": duplicate name, already defined in period_limits[0]
+limitconfig: period_limits[2] "": name is required
+limitconfig: period_limits[2] "": unknown algorithm "token", want one of "fixed_window", "sliding_log", "sliding_window"
+limitconfig: period_limits[2] "": quota must be positive, got 0
+limitconfig: period_limits[2] "": period must be a positive multiple of a second, got 1.5s
+limitconfig: period_limits[2] "": store is required
+limitconfig: period_failure_limits[0] "password": algorithm "sliding_log" is not supported, want "fixed_window"`, err.Error())
+}
+
+func TestConfig_ValidateSliding(t *testing.T) {
+	c := &Config{
+		PeriodLimits: []PeriodLimitConfig{
+			{Name: "a", Algorithm: AlgorithmSlidingWindow, Quota: 5, Period: Duration(time.Hour), Align: true, Store: "redis"},
+			{Name: "b", Algorithm: AlgorithmSlidingLog, Quota: 5, Calendar: CalendarDay, Location: "UTC", Store: "redis"},
+		},
+	}
+	err := c.Validate()
+	require.Error(t, err)
+	assert.EqualError(t, err, `limitconfig: period_limits[0] "a": align is not allowed with algorithm "sliding_window"
+limitconfig: period_limits[1] "b": calendar is not allowed with algorithm "sliding_log"`)
+}
+
+func TestConfig_ValidateCalendar(t *testing.T) {
+	c := &Config{
+		PeriodLimits: []PeriodLimitConfig{
+			{Name: "export", Quota: 5, Calendar: CalendarMonth, Location: "Asia/Shanghai", Store: "redis"},
+		},
+	}
+	require.NoError(t, c.Validate())
+
+	c = &Config{
+		PeriodLimits: []PeriodLimitConfig{
+			{Name: "a", Quota: 5, Calendar: "year", Period: Duration(time.Hour), Align: true, Store: "redis"},
+			{Name: "b", Quota: 5, Calendar: CalendarWeek, Location: "Mars/Olympus", Store: "redis"},
+			{Name: "c", Quota: 5, Period: Duration(time.Hour), Location: "UTC", Store: "redis"},
+		},
+	}
+	err := c.Validate()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `limitconfig: period_limits[0] "a": unknown calendar "year", want one of "hour", "day", "week", "month"
+limitconfig: period_limits[0] "a": period is not allowed with calendar
+limitconfig: period_limits[0] "a": align is not allowed with calendar
+limitconfig: period_limits[0] "a": location is required with calendar, such as "Asia/Shanghai"
+limitconfig: period_limits[1] "b": invalid location "Mars/Olympus": `)
+	assert.ErrorContains(t, err, `
+limitconfig: period_limits[2] "c": location requires calendar`)
+}
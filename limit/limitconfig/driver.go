@@ -0,0 +1,94 @@
+package limitconfig
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/things-go/limiter/limit"
+)
+
+var (
+	_ limit.PeriodLimitDriver        = (*periodLimitDriver)(nil)
+	_ limit.PeriodFailureLimitDriver = (*periodFailureLimitDriver)(nil)
+)
+
+// periodLimitDriver a PeriodLimitDriver registered in the manager once,
+// the underlying driver is replaced atomically on reload.
+type periodLimitDriver struct {
+	entry atomic.Pointer[periodLimitEntry]
+}
+
+type periodLimitEntry struct {
+	config PeriodLimitConfig
+	driver limit.PeriodLimitDriver
+}
+
+func (p *periodLimitDriver) load() limit.PeriodLimitDriver { return p.entry.Load().driver }
+
+func (p *periodLimitDriver) Take(ctx context.Context, key string) (limit.PeriodLimitState, error) {
+	return p.load().Take(ctx, key)
+}
+func (p *periodLimitDriver) TakeN(ctx context.Context, key string, n int) (limit.PeriodLimitState, error) {
+	return p.load().TakeN(ctx, key, n)
+}
+func (p *periodLimitDriver) TakeWithResult(ctx context.Context, key string) (*limit.TakeResult, error) {
+	return p.load().TakeWithResult(ctx, key)
+}
+func (p *periodLimitDriver) TakeNWithResult(ctx context.Context, key string, n int) (*limit.TakeResult, error) {
+	return p.load().TakeNWithResult(ctx, key, n)
+}
+func (p *periodLimitDriver) Refund(ctx context.Context, key string, n int) error {
+	return p.load().Refund(ctx, key, n)
+}
+func (p *periodLimitDriver) CanRefund() bool {
+	if r, ok := p.load().(interface{ CanRefund() bool }); ok {
+		return r.CanRefund()
+	}
+	return true
+}
+func (p *periodLimitDriver) SetQuotaFull(ctx context.Context, key string) error {
+	return p.load().SetQuotaFull(ctx, key)
+}
+func (p *periodLimitDriver) Del(ctx context.Context, key string) error {
+	return p.load().Del(ctx, key)
+}
+func (p *periodLimitDriver) GetRunValue(ctx context.Context, key string) (*limit.RunValue, error) {
+	return p.load().GetRunValue(ctx, key)
+}
+func (p *periodLimitDriver) Update(opts ...limit.PeriodLimitOption) {
+	p.load().Update(opts...)
+}
+
+// periodFailureLimitDriver a PeriodFailureLimitDriver registered in the manager once,
+// the underlying driver is replaced atomically on reload.
+type periodFailureLimitDriver struct {
+	entry atomic.Pointer[periodFailureLimitEntry]
+}
+
+type periodFailureLimitEntry struct {
+	config PeriodLimitConfig
+	driver limit.PeriodFailureLimitDriver
+}
+
+func (p *periodFailureLimitDriver) load() limit.PeriodFailureLimitDriver {
+	return p.entry.Load().driver
+}
+
+func (p *periodFailureLimitDriver) CheckErr(ctx context.Context, key string, err error) (limit.PeriodFailureLimitState, error) {
+	return p.load().CheckErr(ctx, key, err)
+}
+func (p *periodFailureLimitDriver) Check(ctx context.Context, key string, success bool) (limit.PeriodFailureLimitState, error) {
+	return p.load().Check(ctx, key, success)
+}
+func (p *periodFailureLimitDriver) SetQuotaFull(ctx context.Context, key string) error {
+	return p.load().SetQuotaFull(ctx, key)
+}
+func (p *periodFailureLimitDriver) Del(ctx context.Context, key string) error {
+	return p.load().Del(ctx, key)
+}
+func (p *periodFailureLimitDriver) GetRunValue(ctx context.Context, key string) (*limit.RunValue, error) {
+	return p.load().GetRunValue(ctx, key)
+}
+func (p *periodFailureLimitDriver) Update(opts ...limit.PeriodLimitOption) {
+	p.load().Update(opts...)
+}
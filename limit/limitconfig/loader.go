@@ -0,0 +1,331 @@
+package limitconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/things-go/limiter/limit"
+)
+
+// default key prefix, the name of the limiter is appended.
+const (
+	DefaultPeriodKeyPrefix        = "limit:period:"
+	DefaultPeriodFailureKeyPrefix = "limit:period:failure:"
+)
+
+// Store the storages referenced by the store of the limiters, such as the stores of a redis client.
+type Store struct {
+	// Period the storages of the period limits by algorithm.
+	Period map[Algorithm]limit.PeriodStorage
+	// PeriodFailure the storage of the period failure limits.
+	PeriodFailure limit.PeriodFailureStorage
+}
+
+// Loader loads the configuration file, builds and registers the drivers to the managers.
+// the drivers are registered once, the limiters are updated or replaced atomically on reload,
+// so the drivers acquired from the managers always follow the latest configuration.
+// the limiters removed from the configuration become unsupported.
+type Loader struct {
+	path string
+	opt  *options
+
+	mu       sync.Mutex
+	data     []byte
+	periods  map[string]*periodLimitDriver
+	failures map[string]*periodFailureLimitDriver
+
+	closeOnce sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// New loads the configuration file, builds and registers the drivers to the managers,
+// if WithPollInterval is set, it watches the file by polling, use Close to stop.
+func New(path string, opts ...Option) (*Loader, error) {
+	o := &options{
+		stores:         map[string]Store{},
+		periodManager:  limit.NewPeriodLimitManager[string](),
+		failureManager: limit.NewPeriodFailureLimitManager[string](),
+		reloadHook:     func(error) {},
+	}
+	for _, f := range opts {
+		f(o)
+	}
+	if o.format == "" {
+		f, err := FormatFromPath(path)
+		if err != nil {
+			return nil, err
+		}
+		o.format = f
+	}
+	l := &Loader{
+		path:     path,
+		opt:      o,
+		periods:  map[string]*periodLimitDriver{},
+		failures: map[string]*periodFailureLimitDriver{},
+		done:     make(chan struct{}),
+	}
+	if _, err := l.reload(); err != nil {
+		return nil, err
+	}
+	if o.pollInterval > 0 {
+		l.wg.Add(1)
+		go l.watch()
+	}
+	return l, nil
+}
+
+// PeriodLimitManager returns the PeriodLimitManager which the drivers registered to.
+func (l *Loader) PeriodLimitManager() *limit.PeriodLimitManager[string] { return l.opt.periodManager }
+
+// PeriodFailureLimitManager returns the PeriodFailureLimitManager which the drivers registered to.
+func (l *Loader) PeriodFailureLimitManager() *limit.PeriodFailureLimitManager[string] {
+	return l.opt.failureManager
+}
+
+// Reload reloads the configuration file if it is changed,
+// if failed, the previous configuration is kept.
+func (l *Loader) Reload() error {
+	_, err := l.reload()
+	return err
+}
+
+// Apply validates the configuration, then builds and registers the drivers to the managers,
+// if failed, the previous configuration is kept.
+func (l *Loader) Apply(c *Config) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.apply(c)
+}
+
+// Close stops watching the file.
+func (l *Loader) Close() {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		l.wg.Wait()
+	})
+}
+
+func (l *Loader) watch() {
+	defer l.wg.Done()
+	ticker := time.NewTicker(l.opt.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+			if changed, err := l.reload(); changed || err != nil {
+				l.opt.reloadHook(err)
+			}
+		}
+	}
+}
+
+// reload reloads the configuration file, changed reports whether the file content changed.
+// the content is recorded only if applied, so the failed one is retried on the next reload.
+func (l *Loader) reload() (changed bool, err error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return false, fmt.Errorf("limitconfig: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.data != nil && bytes.Equal(l.data, data) {
+		return false, nil
+	}
+	c, err := Parse(data, l.opt.format)
+	if err != nil {
+		return true, fmt.Errorf("%w (%s)", err, l.path)
+	}
+	if err = l.apply(c); err != nil {
+		return true, err
+	}
+	l.data = data
+	return true, nil
+}
+
+func (l *Loader) apply(c *Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if err := l.validateStores(c); err != nil {
+		return err
+	}
+	if err := l.validateNames(c); err != nil {
+		return err
+	}
+
+	// build all the drivers and limiters first, then commit them.
+	var commits []func()
+	newPeriods := make(map[string]*periodLimitDriver)
+	periods := make(map[string]*periodLimitDriver, len(c.PeriodLimits))
+	for _, v := range c.PeriodLimits {
+		d, ok := l.periods[v.Name]
+		if !ok {
+			d = &periodLimitDriver{}
+			d.entry.Store(&periodLimitEntry{driver: limit.UnsupportedPeriodLimitDriver{}})
+			newPeriods[v.Name] = d
+		}
+		periods[v.Name] = d
+		commits = append(commits, l.buildPeriodLimit(d, v))
+	}
+	newFailures := make(map[string]*periodFailureLimitDriver)
+	failures := make(map[string]*periodFailureLimitDriver, len(c.PeriodFailureLimits))
+	for _, v := range c.PeriodFailureLimits {
+		d, ok := l.failures[v.Name]
+		if !ok {
+			d = &periodFailureLimitDriver{}
+			d.entry.Store(&periodFailureLimitEntry{driver: limit.UnsupportedPeriodFailureLimitDriver{}})
+			newFailures[v.Name] = d
+		}
+		failures[v.Name] = d
+		commits = append(commits, l.buildPeriodFailureLimit(d, v))
+	}
+
+	for name, d := range newPeriods {
+		if err := l.opt.periodManager.Register(name, d); err != nil {
+			// registered by others concurrently after validateNames.
+			return fmt.Errorf("limitconfig: period limit %q: %w", name, err)
+		}
+		l.periods[name] = d
+	}
+	for name, d := range newFailures {
+		if err := l.opt.failureManager.Register(name, d); err != nil {
+			// registered by others concurrently after validateNames.
+			return fmt.Errorf("limitconfig: period failure limit %q: %w", name, err)
+		}
+		l.failures[name] = d
+	}
+	for _, commit := range commits {
+		commit()
+	}
+	for name, d := range l.periods {
+		if _, ok := periods[name]; !ok {
+			d.entry.Store(&periodLimitEntry{driver: limit.UnsupportedPeriodLimitDriver{}})
+		}
+	}
+	for name, d := range l.failures {
+		if _, ok := failures[name]; !ok {
+			d.entry.Store(&periodFailureLimitEntry{driver: limit.UnsupportedPeriodFailureLimitDriver{}})
+		}
+	}
+	return nil
+}
+
+// buildPeriodLimit returns the function to apply the limiter, it updates the limiter in place
+// if only the quota, period, calendar or key prefix changed, otherwise replaces it with a new one.
+func (l *Loader) buildPeriodLimit(d *periodLimitDriver, c PeriodLimitConfig) func() {
+	old := d.entry.Load()
+	switch {
+	case old.config == c:
+		return func() {}
+	case sameLimiter(old.config, c):
+		return func() {
+			old.driver.Update(c.options(DefaultPeriodKeyPrefix)...)
+			d.entry.Store(&periodLimitEntry{config: c, driver: old.driver})
+		}
+	default:
+		store := l.opt.stores[c.Store].Period[c.algorithm()]
+		entry := &periodLimitEntry{
+			config: c,
+			driver: limit.NewPeriodLimit(store, c.options(DefaultPeriodKeyPrefix)...),
+		}
+		return func() { d.entry.Store(entry) }
+	}
+}
+
+// buildPeriodFailureLimit returns the function to apply the limiter, it updates the limiter in place
+// if only the quota, period, calendar or key prefix changed, otherwise replaces it with a new one.
+func (l *Loader) buildPeriodFailureLimit(d *periodFailureLimitDriver, c PeriodLimitConfig) func() {
+	old := d.entry.Load()
+	switch {
+	case old.config == c:
+		return func() {}
+	case sameLimiter(old.config, c):
+		return func() {
+			old.driver.Update(c.options(DefaultPeriodFailureKeyPrefix)...)
+			d.entry.Store(&periodFailureLimitEntry{config: c, driver: old.driver})
+		}
+	default:
+		store := l.opt.stores[c.Store].PeriodFailure
+		entry := &periodFailureLimitEntry{
+			config: c,
+			driver: limit.NewPeriodFailureLimit(store, c.options(DefaultPeriodFailureKeyPrefix)...),
+		}
+		return func() { d.entry.Store(entry) }
+	}
+}
+
+func (l *Loader) validateStores(c *Config) error {
+	var errs []error
+	for i, v := range c.PeriodLimits {
+		s, ok := l.opt.stores[v.Store]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("limitconfig: period_limits[%d] %q: unknown store %q", i, v.Name, v.Store))
+		case s.Period[v.algorithm()] == nil:
+			errs = append(errs, fmt.Errorf("limitconfig: period_limits[%d] %q: store %q does not provide algorithm %q",
+				i, v.Name, v.Store, v.algorithm()))
+		}
+	}
+	for i, v := range c.PeriodFailureLimits {
+		s, ok := l.opt.stores[v.Store]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("limitconfig: period_failure_limits[%d] %q: unknown store %q", i, v.Name, v.Store))
+		case s.PeriodFailure == nil:
+			errs = append(errs, fmt.Errorf("limitconfig: period_failure_limits[%d] %q: store %q does not provide period failure storage",
+				i, v.Name, v.Store))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateNames checks the new drivers are not registered to the managers by others,
+// so the drivers are registered all or none.
+func (l *Loader) validateNames(c *Config) error {
+	var errs []error
+	for _, v := range c.PeriodLimits {
+		if _, ok := l.periods[v.Name]; !ok && l.opt.periodManager.Registered(v.Name) {
+			errs = append(errs, fmt.Errorf("limitconfig: period limit %q: %w", v.Name, limit.ErrDuplicateDriver))
+		}
+	}
+	for _, v := range c.PeriodFailureLimits {
+		if _, ok := l.failures[v.Name]; !ok && l.opt.failureManager.Registered(v.Name) {
+			errs = append(errs, fmt.Errorf("limitconfig: period failure limit %q: %w", v.Name, limit.ErrDuplicateDriver))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sameLimiter reports whether the limiter of a can be updated to b in place,
+// the alignment and the calendar can not be turned off by limit.PeriodLimitOption.
+func sameLimiter(a, b PeriodLimitConfig) bool {
+	return a.Store != "" &&
+		a.Store == b.Store &&
+		a.algorithm() == b.algorithm() &&
+		a.Align == b.Align &&
+		(a.Calendar == "") == (b.Calendar == "")
+}
+
+// options returns the options of the limiter.
+func (c *PeriodLimitConfig) options(defaultPrefix string) []limit.PeriodLimitOption {
+	opts := []limit.PeriodLimitOption{
+		limit.WithKeyPrefix(c.keyPrefix(defaultPrefix)),
+		limit.WithPeriod(time.Duration(c.Period)),
+		limit.WithQuota(c.Quota),
+	}
+	if c.Align {
+		opts = append(opts, limit.WithAlign())
+	}
+	if c.Calendar != "" {
+		loc, _ := time.LoadLocation(c.Location) // validated
+		opts = append(opts, limit.WithCalendarAlign(calendarUnits[c.Calendar], loc))
+	}
+	return opts
+}
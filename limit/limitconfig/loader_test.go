@@ -0,0 +1,299 @@
+package limitconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/things-go/limiter/limit"
+	"github.com/things-go/limiter/limit/memory"
+)
+
+func newTestStore(t *testing.T) Store {
+	period := memory.NewPeriodStore()
+	failure := memory.NewPeriodFailureStore()
+	t.Cleanup(func() {
+		period.Close()
+		failure.Close()
+	})
+	return Store{
+		Period: map[Algorithm]limit.PeriodStorage{
+			AlgorithmFixedWindow: period,
+		},
+		PeriodFailure: failure,
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+}
+
+func TestLoader(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "limit.yaml")
+	writeFile(t, path, `
+period_limits:
+  - name: sms
+    quota: 2
+    period: 1m
+    store: memory
+  - name: export
+    quota: 1
+    period: 1m
+    store: memory
+period_failure_limits:
+  - name: password
+    quota: 1
+    period: 1m
+    store: memory
+`)
+	l, err := New(path, WithStore("memory", newTestStore(t)))
+	require.NoError(t, err)
+	defer l.Close()
+
+	sms := l.PeriodLimitManager().Acquire("sms")
+	r, err := sms.TakeNWithResult(ctx, "13800138000", 2)
+	require.NoError(t, err)
+	assert.True(t, r.State.IsHitQuota())
+	export := l.PeriodLimitManager().Acquire("export")
+	sts, err := export.Take(ctx, "user")
+	require.NoError(t, err)
+	assert.True(t, sts.IsHitQuota())
+	password := l.PeriodFailureLimitManager().Acquire("password")
+	fsts, err := password.Check(ctx, "user", false)
+	require.NoError(t, err)
+	assert.True(t, fsts.IsWithinQuota())
+
+	// the quota is updated in place, the counters are kept.
+	writeFile(t, path, `
+period_limits:
+  - name: sms
+    quota: 3
+    period: 1m
+    store: memory
+period_failure_limits:
+  - name: password
+    quota: 2
+    period: 1m
+    store: memory
+`)
+	require.NoError(t, l.Reload())
+	r, err = sms.TakeWithResult(ctx, "13800138000")
+	require.NoError(t, err)
+	assert.True(t, r.State.IsHitQuota())
+	assert.Equal(t, 3, r.Limit)
+	fsts, err = password.Check(ctx, "user", false)
+	require.NoError(t, err)
+	assert.True(t, fsts.IsWithinQuota())
+
+	// removed
+	_, err = export.Take(ctx, "user")
+	assert.ErrorIs(t, err, limit.ErrUnsupportedDriver)
+
+	// invalid configuration keeps the previous one.
+	writeFile(t, path, `
+period_limits:
+  - name: sms
+    quota: 0
+    period: 1m
+    store: memory
+`)
+	err = l.Reload()
+	assert.EqualError(t, err, `limitconfig: period_limits[0] "sms": quota must be positive, got 0`)
+	r, err = sms.TakeWithResult(ctx, "13800138001")
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.Limit)
+	// the failed one is retried even if not changed.
+	assert.EqualError(t, l.Reload(), `limitconfig: period_limits[0] "sms": quota must be positive, got 0`)
+
+	// the file is read while being written.
+	writeFile(t, path, "")
+	assert.ErrorIs(t, l.Reload(), ErrEmptyConfig)
+	writeFile(t, path, "period_limits:\n")
+	assert.ErrorIs(t, l.Reload(), ErrEmptyConfig)
+	r, err = sms.TakeWithResult(ctx, "13800138001")
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.Limit)
+}
+
+func TestLoader_KeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "limit.yaml")
+	writeFile(t, path, `
+period_limits:
+  - {name: sms, quota: 5, period: 1m, store: memory}
+  - {name: sms2, quota: 5, period: 1m, store: memory}
+  - {name: export, quota: 5, period: 1m, key_prefix: "limit:export", store: memory}
+period_failure_limits:
+  - {name: password, quota: 5, period: 1m, store: memory}
+`)
+	store := newTestStore(t)
+	l, err := New(path, WithStore("memory", store))
+	require.NoError(t, err)
+	defer l.Close()
+
+	_, err = l.PeriodLimitManager().Acquire("sms").Take(ctx, "2x")
+	require.NoError(t, err)
+	_, err = l.PeriodLimitManager().Acquire("export").Take(ctx, "user")
+	require.NoError(t, err)
+	_, err = l.PeriodFailureLimitManager().Acquire("password").Check(ctx, "user", false)
+	require.NoError(t, err)
+
+	for _, key := range []string{
+		"limit:period:sms:2x",
+		"limit:export:user",
+	} {
+		tb, err := store.Period[AlgorithmFixedWindow].GetRunValue(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), tb[0], key)
+	}
+	// no collision between sms and sms2
+	v, err := l.PeriodLimitManager().Acquire("sms2").GetRunValue(ctx, "x")
+	require.NoError(t, err)
+	assert.False(t, v.Exist)
+
+	tb, err := store.PeriodFailure.GetRunValue(ctx, "limit:period:failure:password:user")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tb[0])
+}
+
+func TestLoader_Replace(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "limit.json")
+	writeFile(t, path, `{"period_limits": [{"name": "sms", "quota": 1, "period": "1m", "store": "memory"}]}`)
+
+	store := newTestStore(t)
+	slidingWindow := memory.NewPeriodStore()
+	defer slidingWindow.Close()
+	store.Period[AlgorithmSlidingWindow] = slidingWindow
+	l, err := New(path, WithStore("memory", store))
+	require.NoError(t, err)
+	defer l.Close()
+
+	sms := l.PeriodLimitManager().Acquire("sms")
+	sts, err := sms.Take(ctx, "user")
+	require.NoError(t, err)
+	assert.True(t, sts.IsHitQuota())
+
+	// the algorithm changed, the limiter is replaced.
+	writeFile(t, path, `{"period_limits": [{"name": "sms", "algorithm": "sliding_window", "quota": 1, "period": "1m", "store": "memory"}]}`)
+	require.NoError(t, l.Reload())
+	sts, err = sms.Take(ctx, "user")
+	require.NoError(t, err)
+	assert.True(t, sts.IsHitQuota())
+}
+
+func TestLoader_Calendar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limit.yaml")
+	writeFile(t, path, `
+period_limits:
+  - name: export
+    quota: 5
+    calendar: month
+    location: Asia/Shanghai
+    store: memory
+`)
+	l, err := New(path, WithStore("memory", newTestStore(t)))
+	require.NoError(t, err)
+	defer l.Close()
+
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	now := time.Now()
+	r, err := l.PeriodLimitManager().Acquire("export").TakeWithResult(context.Background(), "user")
+	require.NoError(t, err)
+	assert.True(t, r.State.IsAllowed())
+	assert.InDelta(t, limit.CalendarMonth.Next(now, loc).Sub(now), r.ResetAfter, float64(2*time.Second))
+}
+
+func TestLoader_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limit.yaml")
+	writeFile(t, path, "period_limits:\n  - {name: sms, quota: 1, period: 1m, store: memory}\n")
+
+	reloaded := make(chan error, 1)
+	l, err := New(
+		path,
+		WithStore("memory", newTestStore(t)),
+		WithPollInterval(10*time.Millisecond),
+		WithReloadHook(func(err error) { reloaded <- err }),
+	)
+	require.NoError(t, err)
+	defer l.Close()
+
+	writeFile(t, path, "period_limits:\n  - {name: sms, quota: 5, period: 1m, store: memory}\n")
+	select {
+	case err = <-reloaded:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("not reloaded")
+	}
+	r, err := l.PeriodLimitManager().Acquire("sms").TakeWithResult(context.Background(), "user")
+	require.NoError(t, err)
+	assert.Equal(t, 5, r.Limit)
+}
+
+func TestLoader_Error(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := New(filepath.Join(dir, "limit.toml"))
+	assert.ErrorContains(t, err, "unsupported file extension")
+
+	_, err = New(filepath.Join(dir, "none.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := filepath.Join(dir, "limit.yaml")
+	writeFile(t, path, "period_limits:\n  - {name: sms, algorithm: sliding_log, quota: 1, period: 1m, store: memory}\n")
+	_, err = New(path, WithStore("memory", newTestStore(t)))
+	assert.EqualError(t, err, `limitconfig: period_limits[0] "sms": store "memory" does not provide algorithm "sliding_log"`)
+
+	writeFile(t, path, "period_failure_limits:\n  - {name: password, quota: 1, period: 1m, store: redis}\n")
+	_, err = New(path, WithStore("memory", newTestStore(t)))
+	assert.EqualError(t, err, `limitconfig: period_failure_limits[0] "password": unknown store "redis"`)
+
+	// the driver registered by others.
+	writeFile(t, path, "period_limits:\n  - {name: sms, quota: 1, period: 1m, store: memory}\n")
+	m := limit.NewPeriodLimitManager[string]()
+	require.NoError(t, m.Register("sms", limit.UnsupportedPeriodLimitDriver{}))
+	_, err = New(path, WithStore("memory", newTestStore(t)), WithPeriodLimitManager(m))
+	assert.ErrorIs(t, err, limit.ErrDuplicateDriver)
+}
+
+func TestLoader_RegisterConflict(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "limit.yaml")
+	writeFile(t, path, "period_limits:\n  - {name: sms, quota: 1, period: 1m, store: memory}\n")
+	m := limit.NewPeriodLimitManager[string]()
+	fm := limit.NewPeriodFailureLimitManager[string]()
+	l, err := New(path,
+		WithStore("memory", newTestStore(t)),
+		WithPeriodLimitManager(m),
+		WithPeriodFailureLimitManager(fm),
+	)
+	require.NoError(t, err)
+
+	// the second of the new drivers is registered by others, nothing is changed.
+	require.NoError(t, fm.Register("password", limit.UnsupportedPeriodFailureLimitDriver{}))
+	conflict := `
+period_limits:
+  - {name: sms, quota: 5, period: 1m, store: memory}
+  - {name: export, quota: 5, period: 1m, store: memory}
+period_failure_limits:
+  - {name: password, quota: 5, period: 1m, store: memory}
+`
+	writeFile(t, path, conflict)
+	err = l.Reload()
+	assert.EqualError(t, err, `limitconfig: period failure limit "password": limit: duplicate driver`)
+	assert.False(t, m.Registered("export"))
+	r, err := m.Acquire("sms").TakeWithResult(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, 1, r.Limit)
+
+	// retried on the next reload, even if the file is not changed.
+	assert.ErrorIs(t, l.Reload(), limit.ErrDuplicateDriver)
+	assert.False(t, m.Registered("export"))
+}
@@ -0,0 +1,72 @@
+package limitconfig
+
+import (
+	"time"
+
+	"github.com/things-go/limiter/limit"
+)
+
+// Option loader option
+type Option func(*options)
+
+type options struct {
+	format         Format
+	stores         map[string]Store
+	periodManager  *limit.PeriodLimitManager[string]
+	failureManager *limit.PeriodFailureLimitManager[string]
+	pollInterval   time.Duration
+	reloadHook     func(err error)
+}
+
+// WithFormat set the format of the file.
+// default: detected by the file extension, see FormatFromPath.
+func WithFormat(f Format) Option {
+	return func(o *options) {
+		o.format = f
+	}
+}
+
+// WithStore register a Store with the name, which is referenced by the store of the limiters.
+func WithStore(name string, s Store) Option {
+	return func(o *options) {
+		o.stores[name] = s
+	}
+}
+
+// WithPeriodLimitManager set the PeriodLimitManager which the drivers registered to.
+// default: a new PeriodLimitManager.
+func WithPeriodLimitManager(m *limit.PeriodLimitManager[string]) Option {
+	return func(o *options) {
+		if m != nil {
+			o.periodManager = m
+		}
+	}
+}
+
+// WithPeriodFailureLimitManager set the PeriodFailureLimitManager which the drivers registered to.
+// default: a new PeriodFailureLimitManager.
+func WithPeriodFailureLimitManager(m *limit.PeriodFailureLimitManager[string]) Option {
+	return func(o *options) {
+		if m != nil {
+			o.failureManager = m
+		}
+	}
+}
+
+// WithPollInterval watch the file by polling with the interval, if interval <= 0, the file is not watched.
+// default: 0
+func WithPollInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.pollInterval = d
+	}
+}
+
+// WithReloadHook set the hook called after the watched file changed and reloaded,
+// err is nil if reloaded successfully, otherwise the previous configuration is kept.
+func WithReloadHook(f func(err error)) Option {
+	return func(o *options) {
+		if f != nil {
+			o.reloadHook = f
+		}
+	}
+}
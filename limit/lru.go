@@ -0,0 +1,83 @@
+package limit
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru a bounded, concurrent safe, least recently used cache.
+type lru[K comparable, V any] struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// newLRU returns a lru cache, it holds at most size entries.
+func newLRU[K comparable, V any](size int) *lru[K, V] {
+	if size <= 0 {
+		size = 1
+	}
+	return &lru[K, V]{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[K]*list.Element),
+	}
+}
+
+// GetOrAdd returns the value of the key, if not exist, add the value created by newValue.
+func (c *lru[K, V]) GetOrAdd(key K, newValue func() V) V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*lruEntry[K, V]).value
+	}
+	v := newValue()
+	c.add(key, v)
+	return v
+}
+
+// Get returns the value of the key.
+func (c *lru[K, V]) Get(key K) (v V, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*lruEntry[K, V]).value, true
+	}
+	return v, false
+}
+
+// Add adds or updates the value of the key.
+func (c *lru[K, V]) Add(key K, v V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*lruEntry[K, V]).value = v
+		return
+	}
+	c.add(key, v)
+}
+
+// Len returns the number of entries.
+func (c *lru[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *lru[K, V]) add(key K, v V) {
+	c.items[key] = c.ll.PushFront(&lruEntry[K, V]{key: key, value: v})
+	for c.ll.Len() > c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*lruEntry[K, V]).key)
+	}
+}
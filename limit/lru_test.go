@@ -0,0 +1,34 @@
+package limit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	c := newLRU[string, int](2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	// b is the least recently used.
+	c.Add("c", 3)
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+
+	v = c.GetOrAdd("a", func() int { return 10 })
+	assert.Equal(t, 1, v)
+	v = c.GetOrAdd("d", func() int { return 4 })
+	assert.Equal(t, 4, v)
+	_, ok = c.Get("c")
+	assert.False(t, ok)
+
+	c.Add("a", 11)
+	v, ok = c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 11, v)
+}
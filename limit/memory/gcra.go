@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/things-go/limiter/limit"
+)
+
+var _ limit.GCRAStorage = (*GCRAStore)(nil)
+
+// A GCRAStore is used to limit requests with generic cell rate algorithm in local memory.
+type GCRAStore struct {
+	c *cache
+}
+
+// NewGCRAStore returns a GCRAStore with given options.
+// NOTE: call Close to stop the janitor when the store is no longer used.
+func NewGCRAStore(opts ...Option) *GCRAStore {
+	return &GCRAStore{
+		c: newCache(opts...),
+	}
+}
+
+// Take requests n permits at the current time, same as gcra.lua,
+// it returns [allowed, remaining, retryAfter(microsecond), resetAfter(microsecond)].
+func (g *GCRAStore) Take(_ context.Context, key string, burst int, emissionInterval time.Duration, n int) ([]int64, error) {
+	now := time.Now()
+	interval := emissionInterval.Microseconds()
+	burstOffset := interval * int64(burst)
+	nowMicro := now.UnixMicro()
+
+	s := g.c.getShard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tat := nowMicro
+	if e := s.lookup(key, now.UnixNano()); e != nil && e.value > nowMicro {
+		tat = e.value
+	}
+	newTat := tat + interval*int64(n)
+	diff := nowMicro - (newTat - burstOffset)
+	if diff < 0 {
+		retryAfter := -diff
+		if interval*int64(n) > burstOffset {
+			retryAfter = -1 // never allowed
+		}
+		return []int64{0, floorDiv(nowMicro-(tat-burstOffset), interval), retryAfter, tat - nowMicro}, nil
+	}
+	resetAfter := newTat - nowMicro
+	if resetAfter > 0 {
+		s.items[key] = &entry{
+			value:    newTat,
+			expireAt: now.UnixNano() + resetAfter*int64(time.Microsecond),
+		}
+	}
+	return []int64{1, floorDiv(diff, interval), -1, resetAfter}, nil
+}
+
+// Del delete a permit
+func (g *GCRAStore) Del(_ context.Context, key string) error {
+	g.c.del(key)
+	return nil
+}
+
+// Close stop the janitor.
+func (g *GCRAStore) Close() {
+	g.c.close()
+}
+
+func floorDiv(a, b int64) int64 {
+	if b == 0 {
+		return 0
+	}
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/things-go/limiter/limit/tests"
+)
+
+func TestGCRALimit_Allow(t *testing.T) {
+	store := NewGCRAStore()
+	defer store.Close()
+
+	tests.TestGCRALimit_Allow(t, store)
+}
+
+func TestGCRALimit_Del(t *testing.T) {
+	store := NewGCRAStore()
+	defer store.Close()
+
+	tests.TestGCRALimit_Del(t, store)
+}
@@ -0,0 +1,216 @@
+package memory
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultShardCount default shard count of the store.
+	DefaultShardCount = 32
+	// DefaultCleanupInterval default interval of the janitor which removes expired keys.
+	DefaultCleanupInterval = time.Minute
+)
+
+// Option memory store option
+type Option func(*options)
+
+type options struct {
+	shardCount      int
+	cleanupInterval time.Duration
+}
+
+// WithShardCount set shard count, it will be rounded up to a power of two.
+// default: DefaultShardCount
+func WithShardCount(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.shardCount = n
+		}
+	}
+}
+
+// WithCleanupInterval set the interval of the janitor which removes expired keys.
+// if v <= 0, the janitor will be disabled, expired keys only removed when accessed.
+// default: DefaultCleanupInterval
+func WithCleanupInterval(v time.Duration) Option {
+	return func(o *options) {
+		o.cleanupInterval = v
+	}
+}
+
+type entry struct {
+	value int64
+	// expireAt unix nano, 0 means never expire.
+	expireAt int64
+}
+
+func (e *entry) expired(now int64) bool {
+	return e.expireAt > 0 && now >= e.expireAt
+}
+
+// expire set the key expire time, same as redis EXPIRE.
+// it reports false if the entry should be deleted immediately.
+func (e *entry) expire(now int64, sec int) bool {
+	if sec <= 0 {
+		return false
+	}
+	e.expireAt = now + int64(sec)*int64(time.Second)
+	return true
+}
+
+// ttl returns the remaining time to live in seconds, same as redis TTL.
+// -1 if the entry has no associated expire.
+func (e *entry) ttl(now int64) int64 {
+	if e.expireAt == 0 {
+		return -1
+	}
+	return (e.expireAt - now + int64(time.Second)/2) / int64(time.Second)
+}
+
+// pttl returns the remaining time to live in milliseconds, same as redis PTTL.
+// -1 if the entry has no associated expire.
+func (e *entry) pttl(now int64) int64 {
+	if e.expireAt == 0 {
+		return -1
+	}
+	return (e.expireAt - now) / int64(time.Millisecond)
+}
+
+type shard struct {
+	mu    sync.Mutex
+	items map[string]*entry
+}
+
+// lookup returns the alive entry of the key, the expired one will be removed.
+// NOTE: the caller must hold the lock.
+func (s *shard) lookup(key string, now int64) *entry {
+	e, ok := s.items[key]
+	if !ok {
+		return nil
+	}
+	if e.expired(now) {
+		delete(s.items, key)
+		return nil
+	}
+	return e
+}
+
+func (s *shard) deleteExpired(now int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, e := range s.items {
+		if e.expired(now) {
+			delete(s.items, k)
+		}
+	}
+}
+
+// cache sharded, mutex-protected counters with per-key expiry.
+type cache struct {
+	shards    []*shard
+	mask      uint32
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newCache(opts ...Option) *cache {
+	o := options{
+		shardCount:      DefaultShardCount,
+		cleanupInterval: DefaultCleanupInterval,
+	}
+	for _, f := range opts {
+		f(&o)
+	}
+	n := 1
+	for n < o.shardCount {
+		n <<= 1
+	}
+	c := &cache{
+		shards: make([]*shard, n),
+		mask:   uint32(n - 1),
+		done:   make(chan struct{}),
+	}
+	for i := range c.shards {
+		c.shards[i] = &shard{items: make(map[string]*entry)}
+	}
+	if o.cleanupInterval > 0 {
+		go c.janitor(o.cleanupInterval)
+	}
+	return c
+}
+
+// getShard returns the shard of the key, use fnv-1a hash.
+func (c *cache) getShard(key string) *shard {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= prime32
+	}
+	return c.shards[h&c.mask]
+}
+
+// del delete the key.
+func (c *cache) del(key string) {
+	s := c.getShard(key)
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+}
+
+// runValue returns the run value of the key, same as period_run_value.lua.
+func (c *cache) runValue(key string) []int64 {
+	now := time.Now().UnixNano()
+	s := c.getShard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.lookup(key, now)
+	if e == nil {
+		return []int64{0}
+	}
+	return []int64{1, e.value, e.ttl(now)}
+}
+
+// setQuotaFull set the key value to quota, same as period_set_quota_full.lua.
+func (c *cache) setQuotaFull(key string, quota, expireSec int) {
+	now := time.Now().UnixNano()
+	s := c.getShard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.lookup(key, now)
+	if e == nil {
+		e = &entry{value: int64(quota)}
+		if e.expire(now, expireSec) {
+			s.items[key] = e
+		}
+		return
+	}
+	if e.value < int64(quota) {
+		e.value = int64(quota) // keep ttl
+	}
+}
+
+func (c *cache) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			now := time.Now().UnixNano()
+			for _, s := range c.shards {
+				s.deleteExpired(now)
+			}
+		}
+	}
+}
+
+// close stop the janitor.
+func (c *cache) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
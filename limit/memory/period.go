@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/things-go/limiter/limit"
+)
+
+var _ limit.PeriodStorage = (*PeriodStore)(nil)
+var _ limit.PeriodRefundStorage = (*PeriodStore)(nil)
+var _ limit.PeriodLimitDriver = (*limit.PeriodLimit[*PeriodStore])(nil)
+
+const (
+	// inner code, same as period.lua
+	innerPeriodLimitAllowed   = 0
+	innerPeriodLimitHitQuota  = 1
+	innerPeriodLimitOverQuota = 2
+)
+
+// A PeriodStore is used to limit requests during a period of time in local memory.
+type PeriodStore struct {
+	c *cache
+}
+
+// NewPeriodStore returns a PeriodStore with given options.
+// NOTE: call Close to stop the janitor when the store is no longer used.
+func NewPeriodStore(opts ...Option) *PeriodStore {
+	return &PeriodStore{
+		c: newCache(opts...),
+	}
+}
+
+// Take requests cost permits with context,
+// it returns [state, remaining, resetAfter(millisecond), retryAfter(millisecond)], same as period.lua.
+// it refuses without consuming if the cost exceeds the remaining quota.
+func (p *PeriodStore) Take(_ context.Context, key string, quota, expireSec, cost int) ([]int64, error) {
+	now := time.Now().UnixNano()
+	s := p.c.getShard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.lookup(key, now)
+	if e != nil && e.value+int64(cost) > int64(quota) {
+		ttl := e.pttl(now)
+		retry := ttl
+		if cost > quota {
+			retry = -1 // never
+		}
+		return []int64{innerPeriodLimitOverQuota, max(int64(quota)-e.value, 0), ttl, retry}, nil
+	}
+	if e == nil {
+		if cost > quota {
+			return []int64{innerPeriodLimitOverQuota, int64(quota), 0, -1}, nil
+		}
+		e = &entry{}
+		if e.expire(now, expireSec) {
+			s.items[key] = e
+		}
+	}
+	e.value += int64(cost)
+	code := int64(innerPeriodLimitAllowed)
+	if e.value >= int64(quota) {
+		code = innerPeriodLimitHitQuota
+	}
+	return []int64{code, int64(quota) - e.value, max(e.pttl(now), 0), -1}, nil
+}
+
+// Refund gives back cost permits taken by Take, used for compensation.
+func (p *PeriodStore) Refund(_ context.Context, key string, cost int) error {
+	now := time.Now().UnixNano()
+	s := p.c.getShard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.lookup(key, now); e != nil {
+		e.value = max(e.value-int64(cost), 0)
+	}
+	return nil
+}
+
+// SetQuotaFull set a permit over quota.
+func (p *PeriodStore) SetQuotaFull(_ context.Context, key string, quota, expireSec int) error {
+	p.c.setQuotaFull(key, quota, expireSec)
+	return nil
+}
+
+// Del delete a permit
+func (p *PeriodStore) Del(_ context.Context, key string) error {
+	p.c.del(key)
+	return nil
+}
+
+// GetRunValue get run value
+// Exist: false if key not exist.
+// Count: current count
+// TTL: not set expire time, t = -1
+func (p *PeriodStore) GetRunValue(_ context.Context, key string) ([]int64, error) {
+	return p.c.runValue(key), nil
+}
+
+// Close stop the janitor.
+func (p *PeriodStore) Close() {
+	p.c.close()
+}
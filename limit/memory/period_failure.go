@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/things-go/limiter/limit"
+)
+
+var _ limit.PeriodFailureStorage = (*PeriodFailureStore)(nil)
+var _ limit.PeriodFailureLimitDriver = (*limit.PeriodFailureLimit[*PeriodFailureStore])(nil)
+
+const (
+	// inner code, same as period_failure_fixed.lua
+	innerPeriodFailureLimitCodeSuccess   = 0
+	innerPeriodFailureLimitCodeInQuota   = 1
+	innerPeriodFailureLimitCodeOverQuota = 2
+)
+
+// A PeriodFailureStore is used to limit requests when failure during a period of time in local memory.
+type PeriodFailureStore struct {
+	c *cache
+}
+
+// NewPeriodFailureStore returns a PeriodFailureStore with given options.
+// NOTE: call Close to stop the janitor when the store is no longer used.
+func NewPeriodFailureStore(opts ...Option) *PeriodFailureStore {
+	return &PeriodFailureStore{
+		c: newCache(opts...),
+	}
+}
+
+// Check requests a permit.
+func (p *PeriodFailureStore) Check(_ context.Context, key string, quota, expireSec int, success bool) (int64, error) {
+	now := time.Now().UnixNano()
+	s := p.c.getShard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.lookup(key, now)
+	if success {
+		if e == nil {
+			return innerPeriodFailureLimitCodeSuccess, nil
+		}
+		if e.value < int64(quota) {
+			delete(s.items, key)
+			return innerPeriodFailureLimitCodeSuccess, nil
+		}
+		return innerPeriodFailureLimitCodeOverQuota, nil
+	}
+
+	if e == nil {
+		e = &entry{}
+		s.items[key] = e
+	}
+	e.value++
+	current := e.value
+	if current == 1 && !e.expire(now, expireSec) {
+		delete(s.items, key)
+	}
+	if current <= int64(quota) {
+		return innerPeriodFailureLimitCodeInQuota, nil
+	}
+	return innerPeriodFailureLimitCodeOverQuota, nil
+}
+
+// SetQuotaFull set a permit over quota.
+func (p *PeriodFailureStore) SetQuotaFull(_ context.Context, key string, quota, expireSec int) error {
+	p.c.setQuotaFull(key, quota, expireSec)
+	return nil
+}
+
+// Del delete a permit
+func (p *PeriodFailureStore) Del(_ context.Context, key string) error {
+	p.c.del(key)
+	return nil
+}
+
+// GetRunValue get run value
+// Exist: false if key not exist.
+// Count: current failure count
+// TTL: not set expire time, t = -1
+func (p *PeriodFailureStore) GetRunValue(_ context.Context, key string) ([]int64, error) {
+	return p.c.runValue(key), nil
+}
+
+// Close stop the janitor.
+func (p *PeriodFailureStore) Close() {
+	p.c.close()
+}
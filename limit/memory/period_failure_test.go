@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/things-go/limiter/limit/tests"
+)
+
+func TestPeriodFailureLimit_Check(t *testing.T) {
+	store := NewPeriodFailureStore()
+	defer store.Close()
+
+	tests.TestPeriodFailureLimit_Check(t, store)
+}
+
+func TestPeriodFailureLimit_CheckWithAlign(t *testing.T) {
+	store := NewPeriodFailureStore()
+	defer store.Close()
+
+	tests.TestPeriodFailureLimit_CheckWithAlign(t, store)
+}
+
+func TestPeriodFailureLimit_Check_In_Limit_Failure_Time_Then_Success(t *testing.T) {
+	store := NewPeriodFailureStore()
+	defer store.Close()
+
+	tests.TestPeriodFailureLimit_Check_In_Limit_Failure_Time_Then_Success(t, store)
+}
+
+func TestPeriodFailureLimit_Check_Over_Limit_Failure_Time_Then_Success_Always_OverFailureTimeError(t *testing.T) {
+	store := NewPeriodFailureStore()
+	defer store.Close()
+
+	tests.TestPeriodFailureLimit_Check_Over_Limit_Failure_Time_Then_Success_Always_OverFailureTimeError(t, store)
+}
+
+func TestPeriodFailureLimit_SetQuotaFull(t *testing.T) {
+	store := NewPeriodFailureStore()
+	defer store.Close()
+
+	tests.TestPeriodFailureLimit_SetQuotaFull(t, store)
+}
+
+func TestPeriodFailureLimit_QuotaResolver(t *testing.T) {
+	store := NewPeriodFailureStore()
+	defer store.Close()
+
+	tests.TestPeriodFailureLimit_QuotaResolver(t, store)
+}
+
+func TestPeriodFailureLimit_Update(t *testing.T) {
+	store := NewPeriodFailureStore()
+	defer store.Close()
+
+	tests.TestPeriodFailureLimit_Update(t, store)
+}
+
+func TestPeriodFailureLimit_CheckWithCalendarAlign(t *testing.T) {
+	store := NewPeriodFailureStore()
+	defer store.Close()
+
+	tests.TestPeriodFailureLimit_CheckWithCalendarAlign(t, store)
+}
+
+func TestPeriodFailureLimit_Del(t *testing.T) {
+	store := NewPeriodFailureStore()
+	defer store.Close()
+
+	tests.TestPeriodFailureLimit_Del(t, store)
+}
@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/things-go/limiter/limit"
+	"github.com/things-go/limiter/limit/tests"
+)
+
+func TestPeriodLimit_Take(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_Take(t, store)
+}
+
+func TestPeriodLimit_TakeWithAlign(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_TakeWithAlign(t, store)
+}
+
+func TestPeriodLimit_QuotaFull(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_QuotaFull(t, store)
+}
+
+func TestPeriodLimit_TakeN(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_TakeN(t, store)
+}
+
+func TestPeriodLimit_QuotaResolver(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_QuotaResolver(t, store)
+}
+
+func TestPeriodLimit_Update(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_Update(t, store)
+}
+
+func TestPeriodLimit_TakeWithCalendarAlign(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_TakeWithCalendarAlign(t, store)
+}
+
+func TestPeriodLimit_Refund(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_Refund(t, store)
+}
+
+func TestCompositeLimit_Take(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestCompositeLimit_Take(t, store)
+}
+
+func TestCompositeLimit_RefundUnsupported(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestCompositeLimit_RefundUnsupported(t, store)
+}
+
+func TestCompositeLimit_SameKey(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestCompositeLimit_SameKey(t, store)
+}
+
+func TestPeriodLimit_TakeWithResult(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_TakeWithResult(t, store)
+}
+
+func TestPeriodLimit_SetQuotaFull(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_SetQuotaFull(t, store)
+}
+
+func TestPeriodLimit_Del(t *testing.T) {
+	store := NewPeriodStore()
+	defer store.Close()
+
+	tests.TestPeriodLimit_Del(t, store)
+}
+
+func TestPeriodLimit_Expire(t *testing.T) {
+	store := NewPeriodStore(
+		WithShardCount(3),
+		WithCleanupInterval(10*time.Millisecond),
+	)
+	defer store.Close()
+
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithPeriod(time.Second),
+		limit.WithQuota(1),
+	)
+	sts, err := l.Take(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.True(t, sts.IsHitQuota())
+	sts, err = l.Take(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.True(t, sts.IsOverQuota())
+
+	time.Sleep(time.Second + 50*time.Millisecond)
+
+	// removed by the janitor
+	s := store.c.getShard("limit:period:first")
+	s.mu.Lock()
+	assert.Empty(t, s.items)
+	s.mu.Unlock()
+
+	sts, err = l.Take(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.True(t, sts.IsHitQuota())
+}
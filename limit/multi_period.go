@@ -0,0 +1,207 @@
+package limit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// PeriodWindow limit quota requests during a period of time.
+type PeriodWindow struct {
+	// Period a period of time, must be whole seconds and at least a second.
+	Period time.Duration
+	// Quota limit quota requests during the period, must be positive.
+	Quota int
+}
+
+// MultiTakeResult the result of MultiPeriodLimit take.
+type MultiTakeResult struct {
+	// State the permit state, over quota if any window blocked, hit quota if any window hit quota.
+	State PeriodLimitState
+	// Blocked the index of the first window which blocked the request, -1 if allowed.
+	Blocked int
+	// Remaining the min remaining permits of the windows.
+	Remaining int
+	// RetryAfter the time until all the windows allow the request, -1 if allowed or never allowed.
+	RetryAfter time.Duration
+	// Windows the result of each window in order, the windows are not consumed if blocked.
+	Windows []TakeResult
+}
+
+// A MultiPeriodLimit is used to limit requests with multiple windows for one key,
+// such as 10/second AND 300/minute AND 5000/day.
+// All the windows are checked atomically in one round trip, it consumes all or none.
+type MultiPeriodLimit[S MultiPeriodStorage] struct {
+	// keyPrefix in store
+	keyPrefix string
+	windows   []PeriodWindow
+	isAlign   bool
+	store     S
+}
+
+// NewMultiPeriodLimit returns a MultiPeriodLimit with given windows, the periods must be different.
+// It returns ErrNoPeriodWindow if no window, ErrInvalidPeriodWindow if any window is invalid.
+func NewMultiPeriodLimit[S MultiPeriodStorage](store S, windows []PeriodWindow, opts ...MultiPeriodLimitOption) (*MultiPeriodLimit[S], error) {
+	if err := validatePeriodWindows(windows); err != nil {
+		return nil, err
+	}
+	limiter := &MultiPeriodLimit[S]{
+		keyPrefix: "limit:multi:",
+		windows:   append([]PeriodWindow(nil), windows...),
+		store:     store,
+	}
+	for _, opt := range opts {
+		opt(limiter)
+	}
+	return limiter, nil
+}
+
+// Take is shorthand for TakeN(ctx, key, 1).
+func (p *MultiPeriodLimit[S]) Take(ctx context.Context, key string) (*MultiTakeResult, error) {
+	return p.TakeN(ctx, key, 1)
+}
+
+// TakeN requests n permits in all windows, it refuses without consuming any permit
+// if n exceeds the remaining quota of any window.
+func (p *MultiPeriodLimit[S]) TakeN(ctx context.Context, key string, n int) (*MultiTakeResult, error) {
+	if n <= 0 {
+		return nil, ErrInvalidCost
+	}
+	quotas := make([]int, 0, len(p.windows))
+	expireSecs := make([]int, 0, len(p.windows))
+	for _, w := range p.windows {
+		quotas = append(quotas, w.Quota)
+		expireSecs = append(expireSecs, p.calcExpireSeconds(windowSeconds(w.Period)))
+	}
+	tb, err := p.store.Take(ctx, p.formatKeys(key), quotas, expireSecs, n)
+	if err != nil {
+		return nil, err
+	}
+	state, blocked, windows, err := parseBatchResult(tb, quotas)
+	if err != nil {
+		return nil, err
+	}
+	result := &MultiTakeResult{
+		State:      state,
+		Blocked:    blocked,
+		RetryAfter: -1,
+		Windows:    windows,
+	}
+	for i, r := range windows {
+		if i == 0 || r.Remaining < result.Remaining {
+			result.Remaining = r.Remaining
+		}
+	}
+	if result.State.IsOverQuota() {
+		result.RetryAfter = 0
+		for _, r := range result.Windows {
+			if !r.State.IsOverQuota() {
+				continue
+			}
+			if r.RetryAfter < 0 {
+				result.RetryAfter = -1 // never
+				break
+			}
+			result.RetryAfter = max(result.RetryAfter, r.RetryAfter)
+		}
+	}
+	return result, nil
+}
+
+// Del delete the permits of all windows.
+func (p *MultiPeriodLimit[S]) Del(ctx context.Context, key string) error {
+	return p.store.Del(ctx, p.formatKeys(key)...)
+}
+
+// formatKeys returns the key of each window, the key is wrapped in a hash tag,
+// so that all the keys are in the same slot of redis cluster.
+func (p *MultiPeriodLimit[S]) formatKeys(key string) []string {
+	keys := make([]string, 0, len(p.windows))
+	for _, w := range p.windows {
+		keys = append(keys, p.keyPrefix+"{"+key+"}:"+strconv.Itoa(windowSeconds(w.Period)))
+	}
+	return keys
+}
+
+func (p *MultiPeriodLimit[S]) calcExpireSeconds(period int) int {
+	if p.isAlign {
+		now := time.Now()
+		_, offset := now.Zone()
+		unix := now.Unix() + int64(offset)
+		return period - int(unix%int64(period))
+	}
+	return period
+}
+
+func (p *MultiPeriodLimit[S]) align()                { p.isAlign = true }
+func (p *MultiPeriodLimit[S]) setKeyPrefix(k string) { p.keyPrefix = k }
+
+// validatePeriodWindows checks the quota is positive, the period is whole seconds and at least a second,
+// and the periods are different, as each window is stored in the key suffixed with its seconds.
+func validatePeriodWindows(windows []PeriodWindow) error {
+	if len(windows) == 0 {
+		return ErrNoPeriodWindow
+	}
+	seen := make(map[int]int, len(windows))
+	for i, w := range windows {
+		if w.Quota <= 0 {
+			return fmt.Errorf("%w: windows[%d] quota %d must be positive", ErrInvalidPeriodWindow, i, w.Quota)
+		}
+		if w.Period < time.Second || w.Period%time.Second != 0 {
+			return fmt.Errorf("%w: windows[%d] period %s must be whole seconds", ErrInvalidPeriodWindow, i, w.Period)
+		}
+		secs := windowSeconds(w.Period)
+		if j, ok := seen[secs]; ok {
+			return fmt.Errorf("%w: windows[%d] period %s duplicate with windows[%d]", ErrInvalidPeriodWindow, i, w.Period, j)
+		}
+		seen[secs] = i
+	}
+	return nil
+}
+
+// windowSeconds returns the seconds of the period.
+func windowSeconds(period time.Duration) int {
+	return int(period / time.Second)
+}
+
+// parseBatchResult parses the result of MultiPeriodStorage.Take and PeriodBatchStorage.TakeBatch.
+func parseBatchResult(tb []int64, quotas []int) (PeriodLimitState, int, []TakeResult, error) {
+	if len(tb) != 2+4*len(quotas) {
+		return PeriodLimitStsUnknown, -1, nil, ErrUnknownCode
+	}
+	state, err := periodLimitState(tb[0])
+	if err != nil {
+		return PeriodLimitStsUnknown, -1, nil, err
+	}
+	results := make([]TakeResult, 0, len(quotas))
+	for i, quota := range quotas {
+		v := tb[2+4*i : 6+4*i]
+		state, err := periodLimitState(v[0])
+		if err != nil {
+			return PeriodLimitStsUnknown, -1, nil, err
+		}
+		results = append(results, TakeResult{
+			State:      state,
+			Limit:      quota,
+			Remaining:  int(max(v[1], 0)),
+			ResetAfter: milliDuration(v[2]),
+			RetryAfter: milliDuration(v[3]),
+		})
+	}
+	return state, int(tb[1]), results, nil
+}
+
+// periodLimitState returns the PeriodLimitState of the inner code.
+func periodLimitState(code int64) (PeriodLimitState, error) {
+	switch code {
+	case innerPeriodLimitAllowed:
+		return PeriodLimitStsAllowed, nil
+	case innerPeriodLimitHitQuota:
+		return PeriodLimitStsHitQuota, nil
+	case innerPeriodLimitOverQuota:
+		return PeriodLimitStsOverQuota, nil
+	default:
+		return PeriodLimitStsUnknown, ErrUnknownCode
+	}
+}
@@ -0,0 +1,31 @@
+package limit
+
+import (
+	"strings"
+)
+
+// MultiPeriodLimitOptionSetter option setter for MultiPeriodLimit
+type MultiPeriodLimitOptionSetter interface {
+	align()
+	setKeyPrefix(k string)
+}
+
+// MultiPeriodLimitOption defines the method to customize a MultiPeriodLimit.
+type MultiPeriodLimitOption func(l MultiPeriodLimitOptionSetter)
+
+// WithMultiPeriodAlign align each window with the local timezone and the start of its period.
+func WithMultiPeriodAlign() MultiPeriodLimitOption {
+	return func(l MultiPeriodLimitOptionSetter) {
+		l.align()
+	}
+}
+
+// WithMultiPeriodKeyPrefix set key prefix
+func WithMultiPeriodKeyPrefix(k string) MultiPeriodLimitOption {
+	return func(l MultiPeriodLimitOptionSetter) {
+		if !strings.HasSuffix(k, ":") {
+			k += ":"
+		}
+		l.setKeyPrefix(k)
+	}
+}
@@ -2,6 +2,7 @@ package limit
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,66 +15,105 @@ const (
 
 // A PeriodLimit is used to limit requests during a period of time.
 type PeriodLimit[S PeriodStorage] struct {
-	// keyPrefix in redis
-	keyPrefix string
-	// a period seconds of time
-	period int
-	// limit quota requests during a period seconds of time.
-	quota   int
-	isAlign bool
-	store   S
+	config atomic.Pointer[periodConfig]
+	store  S
 }
 
 // NewPeriodLimit returns a PeriodLimit with given parameters.
 func NewPeriodLimit[S PeriodStorage](store S, opts ...PeriodLimitOption) *PeriodLimit[S] {
-	limiter := &PeriodLimit[S]{
-		keyPrefix: "limit:period:",
-		period:    int(24 * time.Hour / time.Second),
-		quota:     6,
-		isAlign:   false,
-		store:     store,
-	}
-	for _, opt := range opts {
-		opt(limiter)
-	}
+	limiter := &PeriodLimit[S]{store: store}
+	limiter.config.Store(newPeriodConfig("limit:period:", opts...))
 	return limiter
 }
 
 // Take requests a permit with context, it returns the permit state.
 func (p *PeriodLimit[S]) Take(ctx context.Context, key string) (PeriodLimitState, error) {
-	code, err := p.store.Take(
+	return p.TakeN(ctx, key, 1)
+}
+
+// TakeN requests n permits with context, it returns the permit state.
+// it refuses without consuming any permit if n exceeds the remaining quota.
+func (p *PeriodLimit[S]) TakeN(ctx context.Context, key string, n int) (PeriodLimitState, error) {
+	r, err := p.TakeNWithResult(ctx, key, n)
+	if err != nil {
+		return PeriodLimitStsUnknown, err
+	}
+	return r.State, nil
+}
+
+// TakeWithResult is shorthand for TakeNWithResult(ctx, key, 1).
+func (p *PeriodLimit[S]) TakeWithResult(ctx context.Context, key string) (*TakeResult, error) {
+	return p.TakeNWithResult(ctx, key, 1)
+}
+
+// TakeNWithResult requests n permits with context, it returns the permit state with
+// the remaining permits, reset and retry after time, in one round trip.
+// it refuses without consuming any permit if n exceeds the remaining quota.
+func (p *PeriodLimit[S]) TakeNWithResult(ctx context.Context, key string, n int) (*TakeResult, error) {
+	if n <= 0 {
+		return nil, ErrInvalidCost
+	}
+	c := p.config.Load()
+	quota, period := c.resolve(ctx, key)
+	tb, err := p.store.Take(
 		ctx,
-		p.formatKey(key),
-		p.quota,
-		p.calcExpireSeconds(),
+		c.formatKey(key),
+		quota,
+		c.calcExpireSeconds(period),
+		n,
 	)
 	if err != nil {
-		return PeriodLimitStsUnknown, err
+		return nil, err
 	}
-	switch code {
-	case innerPeriodLimitAllowed:
-		return PeriodLimitStsAllowed, nil
-	case innerPeriodLimitHitQuota:
-		return PeriodLimitStsHitQuota, nil
-	case innerPeriodLimitOverQuota:
-		return PeriodLimitStsOverQuota, nil
-	default:
-		return PeriodLimitStsUnknown, ErrUnknownCode
+	if len(tb) != 4 {
+		return nil, ErrUnknownCode
+	}
+	state, err := periodLimitState(tb[0])
+	if err != nil {
+		return nil, err
+	}
+	return &TakeResult{
+		State:      state,
+		Limit:      quota,
+		Remaining:  int(max(tb[1], 0)),
+		ResetAfter: milliDuration(tb[2]),
+		RetryAfter: milliDuration(tb[3]),
+	}, nil
+}
+
+// Refund gives back n permits taken by TakeN, used for compensation.
+// it returns ErrRefundUnsupported if the storage is not a PeriodRefundStorage.
+func (p *PeriodLimit[S]) Refund(ctx context.Context, key string, n int) error {
+	if n <= 0 {
+		return ErrInvalidCost
+	}
+	store, ok := any(p.store).(PeriodRefundStorage)
+	if !ok {
+		return ErrRefundUnsupported
 	}
+	return store.Refund(ctx, p.config.Load().formatKey(key), n)
+}
+
+// CanRefund reports whether the storage supports Refund.
+func (p *PeriodLimit[S]) CanRefund() bool {
+	_, ok := any(p.store).(PeriodRefundStorage)
+	return ok
 }
 
 // SetQuotaFull set a permit over quota.
 func (p *PeriodLimit[S]) SetQuotaFull(ctx context.Context, key string) error {
+	c := p.config.Load()
+	quota, period := c.resolve(ctx, key)
 	return p.store.SetQuotaFull(ctx,
-		p.formatKey(key),
-		p.quota,
-		p.calcExpireSeconds(),
+		c.formatKey(key),
+		quota,
+		c.calcExpireSeconds(period),
 	)
 }
 
 // Del delete a permit
 func (p *PeriodLimit[S]) Del(ctx context.Context, key string) error {
-	return p.store.Del(ctx, p.formatKey(key))
+	return p.store.Del(ctx, p.config.Load().formatKey(key))
 }
 
 // GetRunValue get run value
@@ -83,7 +123,7 @@ func (p *PeriodLimit[S]) Del(ctx context.Context, key string) error {
 func (p *PeriodLimit[S]) GetRunValue(ctx context.Context, key string) (*RunValue, error) {
 	tb, err := p.store.GetRunValue(
 		ctx,
-		p.formatKey(key),
+		p.config.Load().formatKey(key),
 	)
 	if err != nil {
 		return nil, err
@@ -116,25 +156,26 @@ func (p *PeriodLimit[S]) GetRunValue(ctx context.Context, key string) (*RunValue
 	}
 }
 
-func (p *PeriodLimit[S]) formatKey(key string) string {
-	return p.keyPrefix + key
-}
-
-func (p *PeriodLimit[S]) calcExpireSeconds() int {
-	if p.isAlign {
-		now := time.Now()
-		_, offset := now.Zone()
-		unix := now.Unix() + int64(offset)
-		return p.period - int(unix%int64(p.period))
+// batchSpec returns the batch storage and the take arguments of the key,
+// ok is false if the storage does not support batch, see CompositeLimit.
+func (p *PeriodLimit[S]) batchSpec(ctx context.Context, key string) (store PeriodBatchStorage, fullKey string, quota, expireSec int, ok bool) {
+	store, ok = any(p.store).(PeriodBatchStorage)
+	if !ok {
+		return nil, "", 0, 0, false
 	}
-	return p.period
+	c := p.config.Load()
+	quota, period := c.resolve(ctx, key)
+	return store, c.formatKey(key), quota, c.calcExpireSeconds(period), true
 }
 
-func (p *PeriodLimit[S]) align()                { p.isAlign = true }
-func (p *PeriodLimit[S]) setKeyPrefix(k string) { p.keyPrefix = k }
-func (p *PeriodLimit[S]) setPeriod(v time.Duration) {
-	if vv := int(v / time.Second); vv > 0 {
-		p.period = int(v / time.Second)
+// Update applies the options on top of the current configuration and replaces it atomically,
+// such as tuning the quota during an incident, in-flight calls keep a consistent view.
+// NOTE: the counters of the old key prefix are not migrated if the key prefix is changed.
+func (p *PeriodLimit[S]) Update(opts ...PeriodLimitOption) {
+	for {
+		old := p.config.Load()
+		if p.config.CompareAndSwap(old, old.clone(opts...)) {
+			return
+		}
 	}
 }
-func (p *PeriodLimit[S]) setQuota(v int) { p.quota = v }
@@ -2,6 +2,7 @@ package limit
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,28 +18,14 @@ const (
 
 // A PeriodFailureLimit is used to limit requests when failure during a period of time.
 type PeriodFailureLimit[S PeriodFailureStorage] struct {
-	// keyPrefix in redis
-	keyPrefix string
-	// a period seconds of time
-	period int
-	// limit quota requests during a period seconds of time.
-	quota   int
-	isAlign bool
-	store   S
+	config atomic.Pointer[periodConfig]
+	store  S
 }
 
 // NewPeriodFailureLimit returns a PeriodFailureLimit with given parameters.
 func NewPeriodFailureLimit[S PeriodFailureStorage](store S, opts ...PeriodLimitOption) *PeriodFailureLimit[S] {
-	limiter := &PeriodFailureLimit[S]{
-		keyPrefix: "limit:period:failure:", // limit:period:failure:
-		period:    int(24 * time.Hour / time.Second),
-		quota:     6,
-		isAlign:   false,
-		store:     store,
-	}
-	for _, opt := range opts {
-		opt(limiter)
-	}
+	limiter := &PeriodFailureLimit[S]{store: store}
+	limiter.config.Store(newPeriodConfig("limit:period:failure:", opts...))
 	return limiter
 }
 
@@ -50,10 +37,12 @@ func (p *PeriodFailureLimit[S]) CheckErr(ctx context.Context, key string, err er
 
 // Check requests a permit.
 func (p *PeriodFailureLimit[S]) Check(ctx context.Context, key string, success bool) (PeriodFailureLimitState, error) {
+	c := p.config.Load()
+	quota, period := c.resolve(ctx, key)
 	code, err := p.store.Check(ctx,
-		p.formatKey(key),
-		p.quota,
-		p.calcExpireSeconds(),
+		c.formatKey(key),
+		quota,
+		c.calcExpireSeconds(period),
 		success,
 	)
 	if err != nil {
@@ -73,16 +62,18 @@ func (p *PeriodFailureLimit[S]) Check(ctx context.Context, key string, success b
 
 // SetQuotaFull set a permit over quota.
 func (p *PeriodFailureLimit[S]) SetQuotaFull(ctx context.Context, key string) error {
+	c := p.config.Load()
+	quota, period := c.resolve(ctx, key)
 	return p.store.SetQuotaFull(ctx,
-		p.formatKey(key),
-		p.quota,
-		p.calcExpireSeconds(),
+		c.formatKey(key),
+		quota,
+		c.calcExpireSeconds(period),
 	)
 }
 
 // Del delete a permit
 func (p *PeriodFailureLimit[S]) Del(ctx context.Context, key string) error {
-	return p.store.Del(ctx, p.formatKey(key))
+	return p.store.Del(ctx, p.config.Load().formatKey(key))
 }
 
 // GetRunValue get run value
@@ -90,7 +81,7 @@ func (p *PeriodFailureLimit[S]) Del(ctx context.Context, key string) error {
 // Count: current failure count
 // TTL: not set expire time, t = -1
 func (p *PeriodFailureLimit[S]) GetRunValue(ctx context.Context, key string) (*RunValue, error) {
-	tb, err := p.store.GetRunValue(ctx, p.formatKey(key))
+	tb, err := p.store.GetRunValue(ctx, p.config.Load().formatKey(key))
 	if err != nil {
 		return nil, err
 	}
@@ -122,25 +113,14 @@ func (p *PeriodFailureLimit[S]) GetRunValue(ctx context.Context, key string) (*R
 	}
 }
 
-func (p *PeriodFailureLimit[S]) formatKey(key string) string {
-	return p.keyPrefix + key
-}
-
-func (p *PeriodFailureLimit[S]) calcExpireSeconds() int {
-	if p.isAlign {
-		now := time.Now()
-		_, offset := now.Zone()
-		unix := now.Unix() + int64(offset)
-		return p.period - int(unix%int64(p.period))
-	}
-	return p.period
-}
-
-func (p *PeriodFailureLimit[S]) align()                { p.isAlign = true }
-func (p *PeriodFailureLimit[S]) setKeyPrefix(k string) { p.keyPrefix = k }
-func (p *PeriodFailureLimit[S]) setPeriod(v time.Duration) {
-	if vv := int(v / time.Second); vv > 0 {
-		p.period = int(v / time.Second)
+// Update applies the options on top of the current configuration and replaces it atomically,
+// such as tuning the quota during an incident, in-flight calls keep a consistent view.
+// NOTE: the counters of the old key prefix are not migrated if the key prefix is changed.
+func (p *PeriodFailureLimit[S]) Update(opts ...PeriodLimitOption) {
+	for {
+		old := p.config.Load()
+		if p.config.CompareAndSwap(old, old.clone(opts...)) {
+			return
+		}
 	}
 }
-func (p *PeriodFailureLimit[S]) setQuota(v int) { p.quota = v }
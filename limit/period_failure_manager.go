@@ -46,6 +46,8 @@ type PeriodFailureLimitDriver interface {
 	// Count: current failure count
 	// TTL: not set expire time, t = -1.
 	GetRunValue(ctx context.Context, key string) (*RunValue, error)
+	// Update applies the options on top of the current configuration atomically.
+	Update(opts ...PeriodLimitOption)
 }
 
 // PeriodFailureLimitManager manage limit period failure
@@ -82,6 +84,14 @@ func (p *PeriodFailureLimitManager[T]) Register(kind T, d PeriodFailureLimitDriv
 	return nil
 }
 
+// Registered reports whether a driver registered with kind.
+func (p *PeriodFailureLimitManager[T]) Registered(kind T) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.driver[kind]
+	return ok
+}
+
 // Acquire driver. if driver not exist. it will return UnsupportedPeriodFailureLimitDriver.
 func (p *PeriodFailureLimitManager[T]) Acquire(kind T) PeriodFailureLimitDriver {
 	p.mu.RLock()
@@ -111,3 +121,4 @@ func (UnsupportedPeriodFailureLimitDriver) Del(context.Context, string) error {
 func (u UnsupportedPeriodFailureLimitDriver) GetRunValue(ctx context.Context, key string) (*RunValue, error) {
 	return nil, ErrUnsupportedDriver
 }
+func (UnsupportedPeriodFailureLimitDriver) Update(...PeriodLimitOption) {}
@@ -24,6 +24,7 @@ func (anotherPeriodFailureLimitDriver) Del(context.Context, string) error {
 func (u anotherPeriodFailureLimitDriver) GetRunValue(ctx context.Context, key string) (*RunValue, error) {
 	return nil, ErrUnsupportedDriver
 }
+func (anotherPeriodFailureLimitDriver) Update(...PeriodLimitOption) {}
 
 func TestPeriodFailureManager(t *testing.T) {
 	var unsupported = "unsupported"
@@ -33,8 +34,10 @@ func TestPeriodFailureManager(t *testing.T) {
 	m := NewPeriodFailureLimitManagerWithDriver(map[string]PeriodFailureLimitDriver{
 		unsupported: unsupportedPeriodFailureLimitKindDriver,
 	})
+	require.False(t, m.Registered(another))
 	err := m.Register(another, anotherDriver)
 	require.Nil(t, err)
+	require.True(t, m.Registered(another))
 
 	err = m.Register(another, anotherDriver)
 	require.ErrorIs(t, err, ErrDuplicateDriver)
@@ -34,6 +34,18 @@ func (p PeriodLimitState) IsOverQuota() bool { return p == PeriodLimitStsOverQuo
 type PeriodLimitDriver interface {
 	// Take requests a permit with context, it returns the permit state.
 	Take(ctx context.Context, key string) (PeriodLimitState, error)
+	// TakeN requests n permits with context, it returns the permit state.
+	// it refuses without consuming any permit if n exceeds the remaining quota.
+	TakeN(ctx context.Context, key string, n int) (PeriodLimitState, error)
+	// TakeWithResult requests a permit with context, it returns the permit state with
+	// the remaining permits, reset and retry after time.
+	TakeWithResult(ctx context.Context, key string) (*TakeResult, error)
+	// TakeNWithResult requests n permits with context, it returns the permit state with
+	// the remaining permits, reset and retry after time.
+	TakeNWithResult(ctx context.Context, key string, n int) (*TakeResult, error)
+	// Refund gives back n permits taken by TakeN, used for compensation.
+	// it returns ErrRefundUnsupported if the storage does not support refund.
+	Refund(ctx context.Context, key string, n int) error
 	// SetQuotaFull set a permit over quota.
 	SetQuotaFull(ctx context.Context, key string) error
 	// Del delete a permit
@@ -43,6 +55,8 @@ type PeriodLimitDriver interface {
 	// Count: current count
 	// TTL: not set expire time, t = -1.
 	GetRunValue(ctx context.Context, key string) (*RunValue, error)
+	// Update applies the options on top of the current configuration atomically.
+	Update(opts ...PeriodLimitOption)
 }
 
 // PeriodLimitManager manage limit period
@@ -79,6 +93,14 @@ func (p *PeriodLimitManager[T]) Register(kind T, d PeriodLimitDriver) error {
 	return nil
 }
 
+// Registered reports whether a driver registered with kind.
+func (p *PeriodLimitManager[T]) Registered(kind T) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.driver[kind]
+	return ok
+}
+
 // Acquire driver. if driver not exist. it will return UnsupportedPeriodLimitDriver.
 func (p *PeriodLimitManager[T]) Acquire(kind T) PeriodLimitDriver {
 	p.mu.RLock()
@@ -96,6 +118,18 @@ type UnsupportedPeriodLimitDriver struct{}
 func (u UnsupportedPeriodLimitDriver) Take(context.Context, string) (PeriodLimitState, error) {
 	return PeriodLimitStsUnknown, ErrUnsupportedDriver
 }
+func (u UnsupportedPeriodLimitDriver) TakeN(context.Context, string, int) (PeriodLimitState, error) {
+	return PeriodLimitStsUnknown, ErrUnsupportedDriver
+}
+func (u UnsupportedPeriodLimitDriver) TakeWithResult(context.Context, string) (*TakeResult, error) {
+	return nil, ErrUnsupportedDriver
+}
+func (u UnsupportedPeriodLimitDriver) TakeNWithResult(context.Context, string, int) (*TakeResult, error) {
+	return nil, ErrUnsupportedDriver
+}
+func (u UnsupportedPeriodLimitDriver) Refund(context.Context, string, int) error {
+	return ErrUnsupportedDriver
+}
 func (u UnsupportedPeriodLimitDriver) SetQuotaFull(context.Context, string) error {
 	return ErrUnsupportedDriver
 }
@@ -105,3 +139,4 @@ func (u UnsupportedPeriodLimitDriver) Del(context.Context, string) error {
 func (u UnsupportedPeriodLimitDriver) GetRunValue(ctx context.Context, key string) (*RunValue, error) {
 	return nil, ErrUnsupportedDriver
 }
+func (u UnsupportedPeriodLimitDriver) Update(...PeriodLimitOption) {}
@@ -12,6 +12,18 @@ type anotherPeriodLimitDriver struct{}
 func (u anotherPeriodLimitDriver) Take(context.Context, string) (PeriodLimitState, error) {
 	return PeriodLimitStsUnknown, ErrUnsupportedDriver
 }
+func (u anotherPeriodLimitDriver) TakeN(context.Context, string, int) (PeriodLimitState, error) {
+	return PeriodLimitStsUnknown, ErrUnsupportedDriver
+}
+func (u anotherPeriodLimitDriver) TakeWithResult(context.Context, string) (*TakeResult, error) {
+	return nil, ErrUnsupportedDriver
+}
+func (u anotherPeriodLimitDriver) TakeNWithResult(context.Context, string, int) (*TakeResult, error) {
+	return nil, ErrUnsupportedDriver
+}
+func (u anotherPeriodLimitDriver) Refund(context.Context, string, int) error {
+	return ErrUnsupportedDriver
+}
 func (u anotherPeriodLimitDriver) SetQuotaFull(context.Context, string) error {
 	return ErrUnsupportedDriver
 }
@@ -21,6 +33,7 @@ func (u anotherPeriodLimitDriver) Del(context.Context, string) error {
 func (u anotherPeriodLimitDriver) GetRunValue(ctx context.Context, key string) (*RunValue, error) {
 	return nil, ErrUnsupportedDriver
 }
+func (u anotherPeriodLimitDriver) Update(...PeriodLimitOption) {}
 
 func TestPeriodManager(t *testing.T) {
 	var unsupported = "unsupported"
@@ -30,8 +43,10 @@ func TestPeriodManager(t *testing.T) {
 	m := NewPeriodLimitManagerWithDriver(map[string]PeriodLimitDriver{
 		unsupported: unsupportedPeriodLimitKindDriver,
 	})
+	require.False(t, m.Registered(another))
 	err := m.Register(another, anotherDriver)
 	require.Nil(t, err)
+	require.True(t, m.Registered(another))
 
 	err = m.Register(another, anotherDriver)
 	require.ErrorIs(t, err, ErrDuplicateDriver)
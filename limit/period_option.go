@@ -1,6 +1,7 @@
 package limit
 
 import (
+	"context"
 	"strings"
 	"time"
 )
@@ -11,6 +12,9 @@ type PeriodLimitOptionSetter interface {
 	setKeyPrefix(k string)
 	setPeriod(v time.Duration)
 	setQuota(v int)
+	setQuotaResolver(f QuotaResolver)
+	setQuotaCache(size int, ttl time.Duration)
+	setCalendarAlign(unit CalendarUnit, loc *time.Location)
 }
 
 // PeriodLimitOption defines the method to customize a PeriodLimit and PeriodFailureLimit.
@@ -25,6 +29,19 @@ func WithAlign() PeriodLimitOption {
 	}
 }
 
+// WithCalendarAlign returns a func to customize a PeriodLimit and PeriodFailureLimit with alignment to the
+// calendar boundary in the location, it takes precedence over WithAlign and the period is ignored.
+// For example, 5 free exports per calendar month in Asia/Shanghai resets exactly at the local month start,
+// even across the daylight saving time changes. if loc is nil, use time.Local.
+func WithCalendarAlign(unit CalendarUnit, loc *time.Location) PeriodLimitOption {
+	return func(l PeriodLimitOptionSetter) {
+		if loc == nil {
+			loc = time.Local
+		}
+		l.setCalendarAlign(unit, loc)
+	}
+}
+
 // WithKeyPrefix set key prefix
 func WithKeyPrefix(k string) PeriodLimitOption {
 	return func(l PeriodLimitOptionSetter) {
@@ -48,3 +65,115 @@ func WithQuota(v int) PeriodLimitOption {
 		l.setQuota(v)
 	}
 }
+
+// WithQuotaResolver resolves the quota and the period per key, such as premium tenants
+// and internal service accounts need different limits on the same endpoint.
+// the resolved values are cached locally, see WithQuotaCache.
+func WithQuotaResolver(f QuotaResolver) PeriodLimitOption {
+	return func(l PeriodLimitOptionSetter) {
+		l.setQuotaResolver(f)
+	}
+}
+
+// WithQuotaCache set the size and the time to live of the quota resolver cache,
+// if ttl <= 0, the cache will be disabled, the resolver is called on every request.
+// default: DefaultQuotaCacheSize, DefaultQuotaCacheTTL
+func WithQuotaCache(size int, ttl time.Duration) PeriodLimitOption {
+	return func(l PeriodLimitOptionSetter) {
+		l.setQuotaCache(size, ttl)
+	}
+}
+
+// periodConfig is an immutable snapshot of the PeriodLimit and PeriodFailureLimit configuration,
+// it is replaced atomically by Update, so in-flight calls keep a consistent view.
+type periodConfig struct {
+	// keyPrefix in redis
+	keyPrefix string
+	// a period seconds of time
+	period int
+	// limit quota requests during a period seconds of time.
+	quota   int
+	isAlign bool
+	// calendar alignment, zero means not aligned with the calendar.
+	calendar CalendarUnit
+	location *time.Location
+	resolver quotaResolver
+}
+
+// newPeriodConfig returns a new config which applied the options.
+func newPeriodConfig(keyPrefix string, opts ...PeriodLimitOption) *periodConfig {
+	c := &periodConfig{
+		keyPrefix: keyPrefix,
+		period:    int(24 * time.Hour / time.Second),
+		quota:     6,
+		isAlign:   false,
+		resolver: quotaResolver{
+			size: DefaultQuotaCacheSize,
+			ttl:  DefaultQuotaCacheTTL,
+		},
+	}
+	return c.apply(opts...)
+}
+
+// clone returns a copy of the config which applied the options,
+// the quota resolver cache is kept unless the resolver or the cache is changed.
+func (c *periodConfig) clone(opts ...PeriodLimitOption) *periodConfig {
+	cc := *c
+	return cc.apply(opts...)
+}
+
+func (c *periodConfig) apply(opts ...PeriodLimitOption) *periodConfig {
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.resolver.cache == nil {
+		c.resolver.init()
+	}
+	return c
+}
+
+func (c *periodConfig) formatKey(key string) string {
+	return c.keyPrefix + key
+}
+
+// resolve returns the quota and the period seconds of the key.
+func (c *periodConfig) resolve(ctx context.Context, key string) (quota, period int) {
+	return c.resolver.resolve(ctx, key, c.quota, c.period)
+}
+
+func (c *periodConfig) calcExpireSeconds(period int) int {
+	if c.calendar > 0 {
+		return calendarExpireSeconds(time.Now(), c.calendar, c.location)
+	}
+	if c.isAlign {
+		now := time.Now()
+		_, offset := now.Zone()
+		unix := now.Unix() + int64(offset)
+		return period - int(unix%int64(period))
+	}
+	return period
+}
+
+func (c *periodConfig) align()                { c.isAlign = true }
+func (c *periodConfig) setKeyPrefix(k string) { c.keyPrefix = k }
+func (c *periodConfig) setPeriod(v time.Duration) {
+	if vv := int(v / time.Second); vv > 0 {
+		c.period = vv
+	}
+}
+func (c *periodConfig) setQuota(v int) { c.quota = v }
+func (c *periodConfig) setCalendarAlign(unit CalendarUnit, loc *time.Location) {
+	if unit >= CalendarHour && unit <= CalendarMonth {
+		c.calendar = unit
+		c.location = loc
+	}
+}
+func (c *periodConfig) setQuotaResolver(f QuotaResolver) {
+	c.resolver.fn = f
+	c.resolver.cache = nil
+}
+func (c *periodConfig) setQuotaCache(size int, ttl time.Duration) {
+	c.resolver.size = size
+	c.resolver.ttl = ttl
+	c.resolver.cache = nil
+}
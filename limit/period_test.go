@@ -66,6 +66,84 @@ func TestPeriodLimit_QuotaFull(t *testing.T) {
 	)
 }
 
+func TestPeriodLimit_TakeN(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_TakeN(
+		t,
+		redisV9.NewPeriodStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodLimit_Refund(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_Refund(
+		t,
+		redisV9.NewPeriodStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestCompositeLimit_Take(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestCompositeLimit_Take(
+		t,
+		redisV9.NewPeriodStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestCompositeLimit_RefundUnsupported(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestCompositeLimit_RefundUnsupported(
+		t,
+		redisV9.NewPeriodStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestCompositeLimit_SameKey(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestCompositeLimit_SameKey(
+		t,
+		redisV9.NewPeriodStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodLimit_TakeWithResult(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_TakeWithResult(
+		t,
+		redisV9.NewPeriodStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
 func TestPeriodLimit_SetQuotaFull(t *testing.T) {
 	mr, err := miniredis.Run()
 	assert.NoError(t, err)
@@ -0,0 +1,67 @@
+package limit
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultQuotaCacheSize default size of the quota resolver cache.
+	DefaultQuotaCacheSize = 1024
+	// DefaultQuotaCacheTTL default time to live of the resolved quota in the cache.
+	DefaultQuotaCacheTTL = time.Minute
+)
+
+// QuotaResolver resolves the quota and the period of the key, such as by the plan tier of the tenant.
+// quota <= 0 or period < time.Second means use the default of the limiter.
+type QuotaResolver func(ctx context.Context, key string) (quota int, period time.Duration)
+
+// quotaResolver resolves the quota and the period of the key with a bounded local cache.
+type quotaResolver struct {
+	fn    QuotaResolver
+	size  int
+	ttl   time.Duration
+	cache *lru[string, resolvedQuota]
+}
+
+type resolvedQuota struct {
+	quota    int
+	period   int // seconds
+	expireAt time.Time
+}
+
+func (r *quotaResolver) init() {
+	if r.fn != nil && r.ttl > 0 {
+		r.cache = newLRU[string, resolvedQuota](r.size)
+	}
+}
+
+// resolve returns the quota and the period seconds of the key, falls back to the defaults.
+func (r *quotaResolver) resolve(ctx context.Context, key string, quota, period int) (int, int) {
+	if r.fn == nil {
+		return quota, period
+	}
+	now := time.Now()
+	v, ok := resolvedQuota{}, false
+	if r.cache != nil {
+		v, ok = r.cache.Get(key)
+	}
+	if !ok || !now.Before(v.expireAt) {
+		q, d := r.fn(ctx, key)
+		v = resolvedQuota{
+			quota:    q,
+			period:   int(d / time.Second),
+			expireAt: now.Add(r.ttl),
+		}
+		if r.cache != nil {
+			r.cache.Add(key, v)
+		}
+	}
+	if v.quota > 0 {
+		quota = v.quota
+	}
+	if v.period > 0 {
+		period = v.period
+	}
+	return quota, period
+}
@@ -0,0 +1,69 @@
+package limit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaResolver(t *testing.T) {
+	var calls int
+	r := quotaResolver{
+		fn: func(_ context.Context, key string) (int, time.Duration) {
+			calls++
+			if key == "premium" {
+				return 100, time.Hour
+			}
+			return 0, 500 * time.Millisecond
+		},
+		size: 2,
+		ttl:  50 * time.Millisecond,
+	}
+	r.init()
+
+	quota, period := r.resolve(context.Background(), "premium", 10, 60)
+	assert.Equal(t, 100, quota)
+	assert.Equal(t, 3600, period)
+	// falls back to the defaults.
+	quota, period = r.resolve(context.Background(), "free", 10, 60)
+	assert.Equal(t, 10, quota)
+	assert.Equal(t, 60, period)
+
+	// cached
+	r.resolve(context.Background(), "premium", 10, 60)
+	r.resolve(context.Background(), "free", 10, 60)
+	assert.Equal(t, 2, calls)
+
+	// expired
+	time.Sleep(60 * time.Millisecond)
+	r.resolve(context.Background(), "premium", 10, 60)
+	assert.Equal(t, 3, calls)
+}
+
+func TestQuotaResolver_NoCache(t *testing.T) {
+	var calls int
+	r := quotaResolver{
+		fn: func(context.Context, string) (int, time.Duration) {
+			calls++
+			return 1, 0
+		},
+		size: DefaultQuotaCacheSize,
+	}
+	r.init()
+
+	for i := 0; i < 3; i++ {
+		quota, period := r.resolve(context.Background(), "key", 10, 60)
+		assert.Equal(t, 1, quota)
+		assert.Equal(t, 60, period)
+	}
+	assert.Equal(t, 3, calls)
+
+	// without resolver
+	r = quotaResolver{}
+	r.init()
+	quota, period := r.resolve(context.Background(), "key", 10, 60)
+	assert.Equal(t, 10, quota)
+	assert.Equal(t, 60, period)
+}
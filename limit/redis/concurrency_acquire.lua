@@ -0,0 +1,19 @@
+-- KEYS[1] as sorted set key, member is the lease, score is the expire time(millisecond) of the lease
+local key = KEYS[1]
+local limit = tonumber(ARGV[1]) -- 最大并发数
+local ttl = tonumber(ARGV[2]) -- 租约有效期(毫秒)
+local lease = ARGV[3] -- 租约
+
+-- 使用redis服务器时间, 避免各实例时钟偏差
+redis.replicate_commands()
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000) -- 当前时间(毫秒)
+
+-- 移除已过期的租约(持有者可能已崩溃)
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now)
+if redis.call("ZCARD", key) >= limit then
+    return 0 -- 超出最大并发数
+end
+redis.call("ZADD", key, now + ttl, lease)
+redis.call("PEXPIRE", key, ttl)
+return 1
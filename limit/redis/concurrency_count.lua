@@ -0,0 +1,7 @@
+local key = KEYS[1]
+
+-- 使用redis服务器时间, 避免各实例时钟偏差
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+return redis.call("ZCOUNT", key, "(" .. now, "+inf")
@@ -0,0 +1,18 @@
+local key = KEYS[1]
+local ttl = tonumber(ARGV[1])
+local lease = ARGV[2]
+
+-- 使用redis服务器时间, 避免各实例时钟偏差
+redis.replicate_commands()
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now)
+if not redis.call("ZSCORE", key, lease) then
+    return 0 -- 租约不存在或已过期
+end
+redis.call("ZADD", key, now + ttl, lease)
+if redis.call("PTTL", key) < ttl then
+    redis.call("PEXPIRE", key, ttl)
+end
+return 1
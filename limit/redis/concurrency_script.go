@@ -0,0 +1,14 @@
+package redis
+
+import (
+	_ "embed"
+)
+
+//go:embed concurrency_acquire.lua
+var ConcurrencyLimitAcquireScript string
+
+//go:embed concurrency_refresh.lua
+var ConcurrencyLimitRefreshScript string
+
+//go:embed concurrency_count.lua
+var ConcurrencyLimitCountScript string
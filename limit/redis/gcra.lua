@@ -0,0 +1,33 @@
+-- KEYS[1] as theoretical arrival time(microsecond) key
+local key = KEYS[1]
+local burst = tonumber(ARGV[1]) -- 突发容量
+local interval = tonumber(ARGV[2]) -- 发放间隔(微秒)
+local cost = tonumber(ARGV[3]) -- 请求数量
+
+-- 使用redis服务器时间, 避免各实例时钟偏差
+redis.replicate_commands()
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2]) -- 当前时间(微秒)
+
+local burst_offset = interval * burst
+local tat = tonumber(redis.call("GET", key))
+if tat == nil or tat < now then
+    tat = now
+end
+
+local new_tat = tat + interval * cost
+local diff = now - (new_tat - burst_offset)
+if diff < 0 then
+    local retry_after = -diff
+    if interval * cost > burst_offset then
+        retry_after = -1 -- never allowed
+    end
+    local remaining = math.floor((now - (tat - burst_offset)) / interval)
+    return { 0, remaining, retry_after, tat - now }
+end
+
+local reset_after = new_tat - now
+if reset_after > 0 then
+    redis.call("SET", key, string.format("%.0f", new_tat), "PX", math.ceil(reset_after / 1000))
+end
+return { 1, math.floor(diff / interval), -1, reset_after }
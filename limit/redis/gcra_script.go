@@ -0,0 +1,8 @@
+package redis
+
+import (
+	_ "embed"
+)
+
+//go:embed gcra.lua
+var GCRALimitScript string
@@ -1,15 +1,28 @@
+-- returns {code, remaining, reset after(millisecond), retry after(millisecond)}
 local key = KEYS[1]
 local quota = tonumber(ARGV[1])
 local window = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
 
-local current = redis.call("INCRBY", key, 1)
-if current == 1 then
+local current = tonumber(redis.call("GET", key) or "0")
+if current + cost > quota then
+    -- over quota, refuse without consuming
+    local ttl = redis.call("PTTL", key)
+    if ttl == -2 then
+        ttl = 0 -- key not exist
+    end
+    local retry = ttl
+    if cost > quota then
+        retry = -1 -- never
+    end
+    return { 2, math.max(quota - current, 0), ttl, retry }
+end
+current = redis.call("INCRBY", key, cost)
+if current == cost then
     redis.call("EXPIRE", key, window)
 end
-if current < quota then
-    return 0 -- allow
-elseif current == quota then
-    return 1 -- hit quota
-else
-    return 2 -- over quata
-end
\ No newline at end of file
+local code = 0 -- allow
+if current >= quota then
+    code = 1 -- hit quota
+end
+return { code, quota - current, redis.call("PTTL", key), -1 }
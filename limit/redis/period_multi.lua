@@ -0,0 +1,59 @@
+-- KEYS[i] as the key of the i-th window
+-- ARGV[3*i-2] as the quota, ARGV[3*i-1] as the window seconds, ARGV[3*i] as the cost of the i-th window
+-- returns {code, blocked window index(0-based, -1 if none), then per window: code, remaining, reset after(millisecond), retry after(millisecond)}
+local n = #KEYS
+local quotas = {}
+local costs = {}
+local currents = {}
+local blocked = -1
+for i = 1, n do
+    quotas[i] = tonumber(ARGV[3 * i - 2])
+    costs[i] = tonumber(ARGV[3 * i])
+    currents[i] = tonumber(redis.call("GET", KEYS[i]) or "0")
+    if blocked < 0 and currents[i] + costs[i] > quotas[i] then
+        blocked = i - 1
+    end
+end
+
+local result = { 0, blocked }
+if blocked >= 0 then
+    -- 任一窗口超出配额, 全部拒绝且不消耗
+    result[1] = 2
+    for i = 1, n do
+        local code, retry = 0, -1
+        local ttl = redis.call("PTTL", KEYS[i])
+        if ttl == -2 then
+            ttl = 0 -- key not exist
+        end
+        if currents[i] + costs[i] > quotas[i] then
+            code = 2 -- over quota
+            if costs[i] <= quotas[i] then
+                retry = ttl
+            end
+        elseif currents[i] + costs[i] == quotas[i] then
+            code = 1 -- would hit quota
+        end
+        table.insert(result, code)
+        table.insert(result, math.max(quotas[i] - currents[i], 0))
+        table.insert(result, ttl)
+        table.insert(result, retry)
+    end
+    return result
+end
+
+for i = 1, n do
+    local current = redis.call("INCRBY", KEYS[i], costs[i])
+    if current == costs[i] then
+        redis.call("EXPIRE", KEYS[i], tonumber(ARGV[3 * i - 1]))
+    end
+    local code = 0 -- allow
+    if current >= quotas[i] then
+        code = 1 -- hit quota
+        result[1] = 1
+    end
+    table.insert(result, code)
+    table.insert(result, quotas[i] - current)
+    table.insert(result, redis.call("PTTL", KEYS[i]))
+    table.insert(result, -1)
+end
+return result
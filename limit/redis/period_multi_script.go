@@ -0,0 +1,8 @@
+package redis
+
+import (
+	_ "embed"
+)
+
+//go:embed period_multi.lua
+var MultiPeriodLimitScript string
@@ -0,0 +1,12 @@
+-- refund the permits, keep the ttl, the count never below zero
+local key = KEYS[1]
+local cost = tonumber(ARGV[1])
+
+if redis.call("EXISTS", key) == 0 then
+    return 0
+end
+local current = redis.call("DECRBY", key, cost)
+if current < 0 then
+    redis.call("INCRBY", key, -current)
+end
+return 0
@@ -12,3 +12,6 @@ var PeriodLimitSetQuotaFullScript string
 
 //go:embed  period_run_value.lua
 var PeriodLimitRunValueScript string
+
+//go:embed  period_refund.lua
+var PeriodLimitRefundScript string
@@ -0,0 +1,46 @@
+-- KEYS[1] as sorted set key, member score is the expire time(millisecond) of the permit
+-- returns {code, remaining, reset after(millisecond), retry after(millisecond)}
+local key = KEYS[1]
+local quota = tonumber(ARGV[1]) -- 限制次数
+local window = tonumber(ARGV[2]) -- 窗口时间(秒)
+local member = ARGV[3] -- 唯一成员
+local cost = tonumber(ARGV[4]) -- 消耗次数
+
+-- 使用redis服务器时间, 避免各实例时钟偏差
+redis.replicate_commands()
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000) -- 当前时间(毫秒)
+
+-- 移除已滑出窗口的记录
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now)
+local current = redis.call("ZCARD", key)
+if current + cost > quota then
+    -- over quota, refuse without consuming
+    local reset = 0
+    local retry = -1 -- never
+    if current > 0 then
+        local last = redis.call("ZRANGE", key, -1, -1, "WITHSCORES")
+        reset = tonumber(last[2]) - now
+    end
+    if cost <= quota then
+        -- 等待足够多最早的记录滑出窗口
+        local idx = current + cost - quota - 1
+        local tb = redis.call("ZRANGE", key, idx, idx, "WITHSCORES")
+        retry = tonumber(tb[2]) - now
+    end
+    return { 2, math.max(quota - current, 0), reset, retry }
+end
+if cost == 1 then
+    redis.call("ZADD", key, now + window * 1000, member)
+else
+    for i = 1, cost do
+        redis.call("ZADD", key, now + window * 1000, member .. ":" .. i)
+    end
+end
+redis.call("EXPIRE", key, window)
+current = current + cost
+local code = 0 -- allow
+if current >= quota then
+    code = 1 -- hit quota
+end
+return { code, quota - current, window * 1000, -1 }
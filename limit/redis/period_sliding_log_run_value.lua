@@ -0,0 +1,21 @@
+local key = KEYS[1]
+
+-- 使用redis服务器时间, 避免各实例时钟偏差
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local tb = {}
+local cnt = tonumber(redis.call("ZCOUNT", key, "(" .. now, "+inf"))
+if cnt == nil or cnt == 0 then
+    tb[1] = 0
+    return tb
+end
+local ttl = tonumber(redis.call("TTL", key))
+if ttl == nil then
+    tb[1] = 0
+    return tb
+end
+tb[1] = 1
+tb[2] = cnt
+tb[3] = ttl
+return tb
@@ -0,0 +1,14 @@
+package redis
+
+import (
+	_ "embed"
+)
+
+//go:embed period_sliding_log.lua
+var PeriodSlidingLogLimitScript string
+
+//go:embed period_sliding_log_set_quota_full.lua
+var PeriodSlidingLogLimitSetQuotaFullScript string
+
+//go:embed period_sliding_log_run_value.lua
+var PeriodSlidingLogLimitRunValueScript string
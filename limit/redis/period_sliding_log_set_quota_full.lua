@@ -0,0 +1,19 @@
+local key = KEYS[1]
+local quota = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local member = ARGV[3]
+
+-- 使用redis服务器时间, 避免各实例时钟偏差
+redis.replicate_commands()
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call("ZREMRANGEBYSCORE", key, "-inf", now)
+local current = redis.call("ZCARD", key)
+if current < quota then
+    for i = current + 1, quota do
+        redis.call("ZADD", key, now + window * 1000, member .. ":" .. i)
+    end
+    redis.call("EXPIRE", key, window)
+end
+return 0
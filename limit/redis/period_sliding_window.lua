@@ -0,0 +1,61 @@
+-- KEYS[1] as hash key, fields: w - current window index, c - current window count, p - previous window count, s - window seconds
+-- returns {code, remaining, reset after(millisecond), retry after(millisecond)}
+local key = KEYS[1]
+local quota = tonumber(ARGV[1]) -- 限制次数
+local window = tonumber(ARGV[2]) -- 窗口时间(秒)
+local cost = tonumber(ARGV[3]) -- 消耗次数
+
+-- 使用redis服务器时间, 避免各实例时钟偏差
+redis.replicate_commands()
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000) -- 当前时间(毫秒)
+
+local size = window * 1000
+local idx = math.floor(now / size)
+local tb = redis.call("HMGET", key, "w", "c", "p")
+local w = tonumber(tb[1])
+local cur = tonumber(tb[2]) or 0
+local prev = tonumber(tb[3]) or 0
+if w == nil or w < idx - 1 then
+    cur = 0
+    prev = 0
+elseif w == idx - 1 then
+    prev = cur
+    cur = 0
+else
+    idx = w -- clock skew, keep the latest window
+end
+
+-- 前一窗口计数按其与滑动窗口的重叠比例加权
+local weight = math.min(1, math.max(0, 1 - (now - idx * size) / size))
+local current = prev * weight + cur
+if current + cost > quota then
+    -- over quota, refuse without consuming
+    local reset = 0
+    if cur > 0 then
+        reset = (idx + 2) * size - now
+    elseif prev > 0 then
+        reset = (idx + 1) * size - now
+    end
+    local retry = -1 -- never
+    if cost <= quota then
+        if cur + cost <= quota then
+            -- 等待前一窗口的权重衰减
+            retry = math.ceil((current + cost - quota) / prev * size)
+        else
+            -- 等待下一窗口, 当前窗口计数成为前一窗口计数
+            retry = (idx + 1) * size - now + math.ceil((1 - (quota - cost) / cur) * size)
+        end
+    end
+    return { 2, math.max(math.floor(quota - current), 0), math.max(reset, 0), math.max(retry, -1) }
+end
+cur = cur + cost
+local reset = math.max(1, (idx + 2) * size - now)
+redis.call("HSET", key, "w", idx, "c", cur, "p", prev, "s", window)
+redis.call("PEXPIRE", key, reset)
+current = current + cost
+local code = 0 -- allow
+if current + 1 > quota then
+    code = 1 -- hit quota
+end
+return { code, math.max(math.floor(quota - current), 0), reset, -1 }
@@ -0,0 +1,10 @@
+-- refund the permits of the window they were taken in, the count never below zero
+local key = KEYS[1]
+local cost = tonumber(ARGV[1])
+
+local cur = tonumber(redis.call("HGET", key, "c"))
+if cur == nil then
+    return 0
+end
+redis.call("HSET", key, "c", math.max(cur - cost, 0))
+return 0
@@ -0,0 +1,37 @@
+local key = KEYS[1]
+
+-- 使用redis服务器时间, 避免各实例时钟偏差
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local tb = {}
+local vals = redis.call("HMGET", key, "w", "c", "p", "s")
+local w = tonumber(vals[1])
+local window = tonumber(vals[4])
+if w == nil or window == nil then
+    tb[1] = 0
+    return tb
+end
+local cur = tonumber(vals[2]) or 0
+local prev = tonumber(vals[3]) or 0
+local size = window * 1000
+local idx = math.floor(now / size)
+if w < idx - 1 then
+    cur = 0
+    prev = 0
+elseif w == idx - 1 then
+    prev = cur
+    cur = 0
+else
+    idx = w
+end
+local ttl = tonumber(redis.call("TTL", key))
+if ttl == nil then
+    tb[1] = 0
+    return tb
+end
+local weight = math.min(1, math.max(0, 1 - (now - idx * size) / size))
+tb[1] = 1
+tb[2] = math.floor(prev * weight + cur)
+tb[3] = ttl
+return tb
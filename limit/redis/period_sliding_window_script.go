@@ -0,0 +1,17 @@
+package redis
+
+import (
+	_ "embed"
+)
+
+//go:embed period_sliding_window.lua
+var PeriodSlidingWindowLimitScript string
+
+//go:embed period_sliding_window_set_quota_full.lua
+var PeriodSlidingWindowLimitSetQuotaFullScript string
+
+//go:embed period_sliding_window_run_value.lua
+var PeriodSlidingWindowLimitRunValueScript string
+
+//go:embed period_sliding_window_refund.lua
+var PeriodSlidingWindowLimitRefundScript string
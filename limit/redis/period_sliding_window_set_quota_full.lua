@@ -0,0 +1,33 @@
+local key = KEYS[1]
+local quota = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+-- 使用redis服务器时间, 避免各实例时钟偏差
+redis.replicate_commands()
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local size = window * 1000
+local idx = math.floor(now / size)
+local tb = redis.call("HMGET", key, "w", "c", "p")
+local w = tonumber(tb[1])
+local cur = tonumber(tb[2]) or 0
+local prev = tonumber(tb[3]) or 0
+if w == nil or w < idx - 1 then
+    cur = 0
+    prev = 0
+elseif w == idx - 1 then
+    prev = cur
+    cur = 0
+else
+    idx = w
+end
+
+local weight = math.min(1, math.max(0, 1 - (now - idx * size) / size))
+local current = prev * weight + cur
+if current < quota then
+    cur = cur + math.ceil(quota - current)
+    redis.call("HSET", key, "w", idx, "c", cur, "p", prev, "s", window)
+    redis.call("PEXPIRE", key, math.max(1, (idx + 2) * size - now))
+end
+return 0
@@ -1,11 +1,11 @@
 -- KEYS[1] as tokens_key
 -- KEYS[2] as timestamp_key
-local rate = tonumber(ARGV[1])
+local rate = tonumber(ARGV[1]) -- 每秒生成令牌数, 可为小数
 local capacity = tonumber(ARGV[2])
-local now = tonumber(ARGV[3])
+local now = tonumber(ARGV[3]) -- 当前时间(微秒)
 local requested = tonumber(ARGV[4])
-local fill_time = capacity/rate
-local ttl = math.floor(fill_time*2)
+local max_wait = tonumber(ARGV[5]) -- 最大等待时间(微秒), 小于0表示不限制
+local fill_time = capacity/rate*1000000 -- 填满令牌桶的时间(微秒)
 local last_tokens = tonumber(redis.call("GET", KEYS[1]))
 if last_tokens == nil then
     last_tokens = capacity
@@ -17,14 +17,25 @@ if last_refreshed == nil then
 end
 
 local delta = math.max(0, now-last_refreshed)
-local filled_tokens = math.min(capacity, last_tokens+(delta*rate))
-local allowed = filled_tokens >= requested
+local filled_tokens = math.min(capacity, last_tokens+(delta*rate/1000000))
+-- 令牌不足时需等待的时间(微秒)
+local wait = 0
+if filled_tokens < requested then
+    wait = math.ceil((requested-filled_tokens)/rate*1000000)
+end
+local allowed = requested <= capacity and (max_wait < 0 or wait <= max_wait)
 local new_tokens = filled_tokens
 if allowed then
+    -- 令牌可能为负, 表示预留了未来的令牌
     new_tokens = filled_tokens - requested
 end
 
-redis.call("SETEX", KEYS[1], ttl, new_tokens)
-redis.call("SETEX", KEYS[2], ttl, now)
+local ttl = math.max(1, math.ceil((fill_time*2 + wait)/1000)) -- 毫秒
+redis.call("PSETEX", KEYS[1], ttl, new_tokens)
+redis.call("PSETEX", KEYS[2], ttl, string.format("%.0f", now))
 
-return allowed
\ No newline at end of file
+local code = 0
+if allowed then
+    code = 1
+end
+return { code, wait, math.floor(new_tokens) }
@@ -12,5 +12,6 @@ var TokenLimitScript string
 const (
 	TokenLimitTokenFormat     = "{%s}.tokens"
 	TokenLimitTimestampFormat = "{%s}.ts"
-	TokenLimitPingInterval    = time.Millisecond * 100
+	// Deprecated: use limit.TokenLimitPingInterval instead.
+	TokenLimitPingInterval = time.Millisecond * 100
 )
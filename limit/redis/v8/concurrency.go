@@ -0,0 +1,73 @@
+package v8
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/things-go/limiter/limit"
+	redisScript "github.com/things-go/limiter/limit/redis"
+)
+
+var _ limit.ConcurrencyStorage = (*ConcurrencyStore)(nil)
+
+// A ConcurrencyStore is used to limit the max in-flight requests per key across instances,
+// the leases are stored in a sorted set with the expire time, read from the redis server clock, as score.
+type ConcurrencyStore struct {
+	store *redis.Client
+}
+
+// NewConcurrencyStore returns a ConcurrencyStore with given parameters.
+func NewConcurrencyStore(store *redis.Client) *ConcurrencyStore {
+	return &ConcurrencyStore{
+		store: store,
+	}
+}
+
+// Acquire acquires a lease which expires after ttl, if the number of alive leases less than limit.
+func (c *ConcurrencyStore) Acquire(ctx context.Context, key, lease string, limit int, ttl time.Duration) (bool, error) {
+	code, err := c.store.Eval(ctx,
+		redisScript.ConcurrencyLimitAcquireScript,
+		[]string{
+			key,
+		},
+		[]string{
+			strconv.Itoa(limit),
+			strconv.FormatInt(ttl.Milliseconds(), 10),
+			lease,
+		},
+	).Int64()
+	return code == 1, err
+}
+
+// Refresh extends the alive lease, it reports false if the lease not exist or expired.
+func (c *ConcurrencyStore) Refresh(ctx context.Context, key, lease string, ttl time.Duration) (bool, error) {
+	code, err := c.store.Eval(ctx,
+		redisScript.ConcurrencyLimitRefreshScript,
+		[]string{
+			key,
+		},
+		[]string{
+			strconv.FormatInt(ttl.Milliseconds(), 10),
+			lease,
+		},
+	).Int64()
+	return code == 1, err
+}
+
+// Release releases the lease.
+func (c *ConcurrencyStore) Release(ctx context.Context, key, lease string) error {
+	return c.store.ZRem(ctx, key, lease).Err()
+}
+
+// Count returns the number of alive leases.
+func (c *ConcurrencyStore) Count(ctx context.Context, key string) (int64, error) {
+	return c.store.Eval(ctx,
+		redisScript.ConcurrencyLimitCountScript,
+		[]string{
+			key,
+		},
+	).Int64()
+}
@@ -0,0 +1,77 @@
+package v8
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/things-go/limiter/limit/tests"
+)
+
+func TestConcurrencyLimit_Acquire(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestConcurrencyLimit_Acquire(
+		t,
+		NewConcurrencyStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestConcurrencyLimit_Expire(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestConcurrencyLimit_Expire(
+		t,
+		NewConcurrencyStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestConcurrencyStore_ServerClock(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	// the redis server clock lags behind the client an hour.
+	serverNow := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	mr.SetTime(serverNow)
+
+	ctx := context.Background()
+	store := NewConcurrencyStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	ok, err := store.Acquire(ctx, "first", "lease", 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	score, err := mr.ZScore("first", "lease")
+	require.NoError(t, err)
+	assert.Equal(t, float64(serverNow.Add(time.Minute).UnixMilli()), score)
+
+	ok, err = store.Refresh(ctx, "first", "lease", 2*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	score, err = mr.ZScore("first", "lease")
+	require.NoError(t, err)
+	assert.Equal(t, float64(serverNow.Add(2*time.Minute).UnixMilli()), score)
+
+	n, err := store.Count(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	// expired by the redis server clock.
+	mr.SetTime(serverNow.Add(3 * time.Minute))
+	n, err = store.Count(ctx, "first")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
@@ -0,0 +1,47 @@
+package v8
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/things-go/limiter/limit"
+	redisScript "github.com/things-go/limiter/limit/redis"
+)
+
+var _ limit.GCRAStorage = (*GCRAStore)(nil)
+
+// A GCRAStore is used to limit requests with generic cell rate algorithm.
+type GCRAStore struct {
+	store *redis.Client
+}
+
+// NewGCRAStore returns a GCRAStore with given parameters.
+func NewGCRAStore(store *redis.Client) *GCRAStore {
+	return &GCRAStore{
+		store: store,
+	}
+}
+
+// Take requests n permits at the current time of the redis server,
+// it returns [allowed, remaining, retryAfter(microsecond), resetAfter(microsecond)].
+func (g *GCRAStore) Take(ctx context.Context, key string, burst int, emissionInterval time.Duration, n int) ([]int64, error) {
+	return g.store.Eval(ctx,
+		redisScript.GCRALimitScript,
+		[]string{
+			key,
+		},
+		[]string{
+			strconv.Itoa(burst),
+			strconv.FormatInt(emissionInterval.Microseconds(), 10),
+			strconv.Itoa(n),
+		},
+	).Int64Slice()
+}
+
+// Del delete a permit
+func (g *GCRAStore) Del(ctx context.Context, key string) error {
+	return g.store.Del(ctx, key).Err()
+}
@@ -0,0 +1,73 @@
+package v8
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/things-go/limiter/limit/tests"
+)
+
+func TestGCRALimit_Allow(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestGCRALimit_Allow(
+		t,
+		NewGCRAStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestGCRALimit_Del(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestGCRALimit_Del(
+		t,
+		NewGCRAStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestGCRALimit_RedisUnavailable(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	addr := mr.Addr()
+	mr.Close()
+
+	tests.TestGCRALimit_RedisUnavailable(
+		t,
+		NewGCRAStore(
+			redis.NewClient(&redis.Options{Addr: addr}),
+		),
+	)
+}
+
+func TestGCRAStore_ServerClock(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	// the redis server clock lags behind the client an hour.
+	serverNow := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	mr.SetTime(serverNow)
+
+	store := NewGCRAStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	tb, err := store.Take(context.Background(), "first", 5, 100*time.Millisecond, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tb[0])
+	tat, err := mr.Get("first")
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(serverNow.Add(100*time.Millisecond).UnixMicro(), 10), tat)
+}
@@ -85,6 +85,45 @@ func TestPeriodFailureLimit_SetQuotaFull(t *testing.T) {
 	)
 }
 
+func TestPeriodFailureLimit_QuotaResolver(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodFailureLimit_QuotaResolver(
+		t,
+		NewPeriodFailureStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodFailureLimit_Update(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodFailureLimit_Update(
+		t,
+		NewPeriodFailureStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodFailureLimit_CheckWithCalendarAlign(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodFailureLimit_CheckWithCalendarAlign(
+		t,
+		NewPeriodFailureStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
 func TestPeriodFailureLimit_Del(t *testing.T) {
 	mr, err := miniredis.Run()
 	assert.Nil(t, err)
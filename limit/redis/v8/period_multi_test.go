@@ -0,0 +1,24 @@
+package v8
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/things-go/limiter/limit/tests"
+)
+
+func TestMultiPeriodLimit_Take(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestMultiPeriodLimit_Take(
+		t,
+		NewMultiPeriodStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
@@ -0,0 +1,165 @@
+package v8
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/things-go/limiter/limit/tests"
+)
+
+func TestPeriodSlidingLogLimit_Take(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	defer mr.Close()
+
+	tests.TestPeriodLimit_Take(
+		t,
+		NewPeriodSlidingLogStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingLogLimit_TakeSliding(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+
+	defer mr.Close()
+
+	tests.TestPeriodLimit_TakeSliding(
+		t,
+		NewPeriodSlidingLogStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingLogLimit_RedisUnavailable(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	addr := mr.Addr()
+
+	mr.Close()
+	tests.TestPeriodLimit_RedisUnavailable(
+		t,
+		NewPeriodSlidingLogStore(
+			redis.NewClient(&redis.Options{Addr: addr}),
+		),
+	)
+}
+
+func TestPeriodSlidingLogLimit_QuotaFull(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_QuotaFull(
+		t,
+		NewPeriodSlidingLogStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingLogLimit_TakeN(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_TakeN(
+		t,
+		NewPeriodSlidingLogStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingLogLimit_RefundUnsupported(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_RefundUnsupported(
+		t,
+		NewPeriodSlidingLogStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingLogLimit_TakeWithResult(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_TakeWithResult(
+		t,
+		NewPeriodSlidingLogStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingLogLimit_SetQuotaFull(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_SetQuotaFull(
+		t,
+		NewPeriodSlidingLogStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingLogLimit_Del(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_Del(
+		t,
+		NewPeriodSlidingLogStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingLogStore_ServerClock(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	// the redis server clock lags behind the client an hour.
+	serverNow := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	mr.SetTime(serverNow)
+
+	ctx := context.Background()
+	store := NewPeriodSlidingLogStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	_, err = store.Take(ctx, "first", 2, 60, 1)
+	require.NoError(t, err)
+	members, err := mr.ZMembers("first")
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	score, err := mr.ZScore("first", members[0])
+	require.NoError(t, err)
+	assert.Equal(t, float64(serverNow.Add(time.Minute).UnixMilli()), score)
+
+	tb, err := store.GetRunValue(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tb[1])
+
+	// slides out of the window by the redis server clock.
+	mr.SetTime(serverNow.Add(time.Minute))
+	tb, err = store.GetRunValue(ctx, "first")
+	require.NoError(t, err)
+	assert.Zero(t, tb[0])
+}
@@ -0,0 +1,93 @@
+package v8
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/things-go/limiter/limit"
+	redisScript "github.com/things-go/limiter/limit/redis"
+)
+
+var _ limit.PeriodStorage = (*PeriodSlidingWindowStore)(nil)
+var _ limit.PeriodRefundStorage = (*PeriodSlidingWindowStore)(nil)
+var _ limit.PeriodLimitDriver = (*limit.PeriodLimit[*PeriodSlidingWindowStore])(nil)
+
+// A PeriodSlidingWindowStore is used to limit requests during an approximate sliding window of time.
+// It keeps the counters of the current and previous fixed window, and weights the previous one
+// by how much of it overlaps the sliding window, O(1) memory per key.
+// NOTE: the window is the period of PeriodLimit, WithAlign should not be used.
+// the time is read from the redis server clock, so the replicas with clock skew share the same window.
+type PeriodSlidingWindowStore struct {
+	store *redis.Client
+}
+
+// NewPeriodSlidingWindowStore returns a PeriodSlidingWindowStore with given parameters.
+func NewPeriodSlidingWindowStore(store *redis.Client) *PeriodSlidingWindowStore {
+	return &PeriodSlidingWindowStore{
+		store: store,
+	}
+}
+
+// Take requests cost permits with context,
+// it returns [state, remaining, resetAfter(millisecond), retryAfter(millisecond)].
+// it refuses without consuming if the cost exceeds the remaining quota.
+func (p *PeriodSlidingWindowStore) Take(ctx context.Context, key string, quota, expireSec, cost int) ([]int64, error) {
+	return p.store.Eval(ctx,
+		redisScript.PeriodSlidingWindowLimitScript,
+		[]string{
+			key,
+		},
+		[]string{
+			strconv.Itoa(quota),
+			strconv.Itoa(expireSec),
+			strconv.Itoa(cost),
+		},
+	).Int64Slice()
+}
+
+// Refund gives back cost permits taken by Take, used for compensation.
+func (p *PeriodSlidingWindowStore) Refund(ctx context.Context, key string, cost int) error {
+	return p.store.Eval(ctx,
+		redisScript.PeriodSlidingWindowLimitRefundScript,
+		[]string{
+			key,
+		},
+		[]string{
+			strconv.Itoa(cost),
+		},
+	).Err()
+}
+
+// SetQuotaFull set a permit over quota.
+func (p *PeriodSlidingWindowStore) SetQuotaFull(ctx context.Context, key string, quota, expireSec int) error {
+	return p.store.Eval(ctx,
+		redisScript.PeriodSlidingWindowLimitSetQuotaFullScript,
+		[]string{
+			key,
+		},
+		[]string{
+			strconv.Itoa(quota),
+			strconv.Itoa(expireSec),
+		},
+	).Err()
+}
+
+// Del delete a permit
+func (p *PeriodSlidingWindowStore) Del(ctx context.Context, key string) error {
+	return p.store.Del(ctx, key).Err()
+}
+
+// GetRunValue get run value
+// Exist: false if key not exist.
+// Count: current weighted count in the window
+// TTL: not set expire time, t = -1
+func (p *PeriodSlidingWindowStore) GetRunValue(ctx context.Context, key string) ([]int64, error) {
+	return p.store.Eval(ctx,
+		redisScript.PeriodSlidingWindowLimitRunValueScript,
+		[]string{
+			key,
+		},
+	).Int64Slice()
+}
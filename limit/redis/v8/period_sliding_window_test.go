@@ -0,0 +1,156 @@
+package v8
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/things-go/limiter/limit/tests"
+)
+
+func TestPeriodSlidingWindowLimit_Take(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	freezeWindow(mr)
+
+	defer mr.Close()
+
+	tests.TestPeriodLimit_Take(
+		t,
+		NewPeriodSlidingWindowStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingWindowLimit_RedisUnavailable(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	addr := mr.Addr()
+
+	mr.Close()
+	tests.TestPeriodLimit_RedisUnavailable(
+		t,
+		NewPeriodSlidingWindowStore(
+			redis.NewClient(&redis.Options{Addr: addr}),
+		),
+	)
+}
+
+func TestPeriodSlidingWindowLimit_QuotaFull(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	freezeWindow(mr)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_QuotaFull(
+		t,
+		NewPeriodSlidingWindowStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingWindowLimit_TakeN(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	freezeWindow(mr)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_TakeN(
+		t,
+		NewPeriodSlidingWindowStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingWindowLimit_Refund(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	freezeWindow(mr)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_Refund(
+		t,
+		NewPeriodSlidingWindowStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingWindowLimit_TakeWithResult(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	freezeWindow(mr)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_TakeWithResult(
+		t,
+		NewPeriodSlidingWindowStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingWindowLimit_SetQuotaFull(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	freezeWindow(mr)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_SetQuotaFull(
+		t,
+		NewPeriodSlidingWindowStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingWindowLimit_Weighted(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	tests.TestPeriodLimit_TakeWeighted(
+		t,
+		NewPeriodSlidingWindowStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestPeriodSlidingWindowStore_ServerClock(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	// the redis server clock lags behind the client an hour.
+	serverNow := time.Now().Add(-time.Hour).Truncate(time.Minute).Add(30 * time.Second)
+	mr.SetTime(serverNow)
+
+	ctx := context.Background()
+	store := NewPeriodSlidingWindowStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	_, err = store.Take(ctx, "first", 4, 60, 2)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatInt(serverNow.Unix()/60, 10), mr.HGet("first", "w"))
+
+	// half of the previous window overlaps by the redis server clock.
+	mr.SetTime(serverNow.Add(time.Minute))
+	tb, err := store.GetRunValue(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tb[0])
+	assert.Equal(t, int64(1), tb[1])
+}
+
+// freezeWindow freezes the redis server clock in the middle of a second,
+// so the assertions of the suites with 1s period never cross a window boundary.
+func freezeWindow(mr *miniredis.Miniredis) {
+	mr.SetTime(time.Now().Truncate(time.Second).Add(time.Second / 2))
+}
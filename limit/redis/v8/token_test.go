@@ -7,6 +7,8 @@ import (
 	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/things-go/limiter/limit/tests"
 )
 
 func TestTokenLimit_Rescue(t *testing.T) {
@@ -30,9 +32,6 @@ func TestTokenLimit_Rescue(t *testing.T) {
 		if l.Allow() {
 			allowed++
 		}
-
-		// make sure start monitor more than once doesn't matter
-		l.startMonitor()
 	}
 
 	assert.True(t, allowed >= burst+rate)
@@ -44,21 +43,12 @@ func TestTokenLimit_Take(t *testing.T) {
 
 	defer mr.Close()
 
-	const (
-		total = 100
-		rate  = 5
-		burst = 10
+	tests.TestTokenLimit_Take(
+		t,
+		NewTokenStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
 	)
-	l := NewTokenLimit(rate, burst, "tokenlimit", redis.NewClient(&redis.Options{Addr: mr.Addr()}))
-	var allowed int
-	for i := 0; i < total; i++ {
-		time.Sleep(time.Second / time.Duration(total))
-		if l.Allow() {
-			allowed++
-		}
-	}
-
-	assert.True(t, allowed >= burst+rate)
 }
 
 func TestTokenLimit_TakeBurst(t *testing.T) {
@@ -67,18 +57,94 @@ func TestTokenLimit_TakeBurst(t *testing.T) {
 
 	defer mr.Close()
 
-	const (
-		total = 100
-		rate  = 5
-		burst = 10
+	tests.TestTokenLimit_TakeBurst(
+		t,
+		NewTokenStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
 	)
-	l := NewTokenLimit(rate, burst, "tokenlimit", redis.NewClient(&redis.Options{Addr: mr.Addr()}))
-	var allowed int
-	for i := 0; i < total; i++ {
-		if l.Allow() {
-			allowed++
-		}
-	}
+}
+
+func TestKeyedTokenLimit_Take(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	defer mr.Close()
+
+	tests.TestKeyedTokenLimit_Take(
+		t,
+		NewTokenStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestTokenLimit_Reserve(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	defer mr.Close()
+
+	tests.TestTokenLimit_Reserve(
+		t,
+		NewTokenStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestTokenLimit_Wait(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	defer mr.Close()
+
+	tests.TestTokenLimit_Wait(
+		t,
+		NewTokenStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestTokenLimit_FractionalRate(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	defer mr.Close()
+
+	tests.TestTokenLimit_FractionalRate(
+		t,
+		NewTokenStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestTokenLimit_TryReserve(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	defer mr.Close()
 
-	assert.True(t, allowed >= burst)
+	tests.TestTokenLimit_TryReserve(
+		t,
+		NewTokenStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
+}
+
+func TestTokenLimit_Update(t *testing.T) {
+	mr, err := miniredis.Run()
+	assert.Nil(t, err)
+
+	defer mr.Close()
+
+	tests.TestTokenLimit_Update(
+		t,
+		NewTokenStore(
+			redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		),
+	)
 }
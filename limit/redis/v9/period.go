@@ -11,6 +11,8 @@ import (
 )
 
 var _ limit.PeriodStorage = (*PeriodStore)(nil)
+var _ limit.PeriodRefundStorage = (*PeriodStore)(nil)
+var _ limit.PeriodBatchStorage = (*PeriodStore)(nil)
 var _ limit.PeriodLimitDriver = (*limit.PeriodLimit[*PeriodStore])(nil)
 
 // A PeriodStore is used to limit requests during a period of time.
@@ -25,8 +27,10 @@ func NewPeriodStore(store *redis.Client) *PeriodStore {
 	}
 }
 
-// Take requests a permit with context, it returns the permit state.
-func (p *PeriodStore) Take(ctx context.Context, key string, quota, expireSec int) (int64, error) {
+// Take requests cost permits with context,
+// it returns [state, remaining, resetAfter(millisecond), retryAfter(millisecond)].
+// it refuses without consuming if the cost exceeds the remaining quota.
+func (p *PeriodStore) Take(ctx context.Context, key string, quota, expireSec, cost int) ([]int64, error) {
 	return p.store.Eval(ctx,
 		redisScript.PeriodLimitScript,
 		[]string{
@@ -35,8 +39,43 @@ func (p *PeriodStore) Take(ctx context.Context, key string, quota, expireSec int
 		[]string{
 			strconv.Itoa(quota),
 			strconv.Itoa(expireSec),
+			strconv.Itoa(cost),
 		},
-	).Int64()
+	).Int64Slice()
+}
+
+// Refund gives back cost permits taken by Take, used for compensation.
+func (p *PeriodStore) Refund(ctx context.Context, key string, cost int) error {
+	return p.store.Eval(ctx,
+		redisScript.PeriodLimitRefundScript,
+		[]string{
+			key,
+		},
+		[]string{
+			strconv.Itoa(cost),
+		},
+	).Err()
+}
+
+// BatchKey returns the redis client, the PeriodStores of the same client can be taken in one batch.
+func (p *PeriodStore) BatchKey() any {
+	return p.store
+}
+
+// TakeBatch requests the costs of all keys atomically, it consumes all or none.
+// it returns [state, blocked key index(-1 if none), then per key:
+// state, remaining, resetAfter(millisecond), retryAfter(millisecond)].
+// NOTE: the keys are not hash tagged, they must be in one redis node.
+func (p *PeriodStore) TakeBatch(ctx context.Context, keys []string, quotas, expireSecs, costs []int) ([]int64, error) {
+	args := make([]string, 0, 3*len(keys))
+	for i := range keys {
+		args = append(args, strconv.Itoa(quotas[i]), strconv.Itoa(expireSecs[i]), strconv.Itoa(costs[i]))
+	}
+	return p.store.Eval(ctx,
+		redisScript.MultiPeriodLimitScript,
+		keys,
+		args,
+	).Int64Slice()
 }
 
 // SetQuotaFull set a permit over quota.
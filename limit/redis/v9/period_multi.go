@@ -0,0 +1,43 @@
+package v9
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/things-go/limiter/limit"
+	redisScript "github.com/things-go/limiter/limit/redis"
+)
+
+var _ limit.MultiPeriodStorage = (*MultiPeriodStore)(nil)
+
+// A MultiPeriodStore is used to limit requests with multiple windows during a period of time.
+type MultiPeriodStore struct {
+	store *redis.Client
+}
+
+// NewMultiPeriodStore returns a MultiPeriodStore with given parameters.
+func NewMultiPeriodStore(store *redis.Client) *MultiPeriodStore {
+	return &MultiPeriodStore{
+		store: store,
+	}
+}
+
+// Take requests cost permits in all windows atomically, it consumes all or none.
+func (p *MultiPeriodStore) Take(ctx context.Context, keys []string, quotas, expireSecs []int, cost int) ([]int64, error) {
+	args := make([]string, 0, 3*len(keys))
+	for i := range keys {
+		args = append(args, strconv.Itoa(quotas[i]), strconv.Itoa(expireSecs[i]), strconv.Itoa(cost))
+	}
+	return p.store.Eval(ctx,
+		redisScript.MultiPeriodLimitScript,
+		keys,
+		args,
+	).Int64Slice()
+}
+
+// Del delete the window keys.
+func (p *MultiPeriodStore) Del(ctx context.Context, keys ...string) error {
+	return p.store.Del(ctx, keys...).Err()
+}
@@ -0,0 +1,91 @@
+package v9
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/things-go/limiter/limit"
+	redisScript "github.com/things-go/limiter/limit/redis"
+)
+
+var _ limit.PeriodStorage = (*PeriodSlidingLogStore)(nil)
+var _ limit.PeriodLimitDriver = (*limit.PeriodLimit[*PeriodSlidingLogStore])(nil)
+
+// A PeriodSlidingLogStore is used to limit requests during a sliding window of time.
+// It records every permit in a sorted set, and trims the permits out of the window,
+// so there is no burst across the window boundary like PeriodStore.
+// NOTE: the window is the period of PeriodLimit, WithAlign should not be used.
+// the time is read from the redis server clock, so the replicas with clock skew share the same window.
+// it does not support refund, the permits of a take can not be told apart from the concurrent ones,
+// so it can not be a member of CompositeLimit.
+type PeriodSlidingLogStore struct {
+	store *redis.Client
+}
+
+// NewPeriodSlidingLogStore returns a PeriodSlidingLogStore with given parameters.
+func NewPeriodSlidingLogStore(store *redis.Client) *PeriodSlidingLogStore {
+	return &PeriodSlidingLogStore{
+		store: store,
+	}
+}
+
+// Take requests cost permits with context,
+// it returns [state, remaining, resetAfter(millisecond), retryAfter(millisecond)].
+// it refuses without consuming if the cost exceeds the remaining quota.
+func (p *PeriodSlidingLogStore) Take(ctx context.Context, key string, quota, expireSec, cost int) ([]int64, error) {
+	return p.store.Eval(ctx,
+		redisScript.PeriodSlidingLogLimitScript,
+		[]string{
+			key,
+		},
+		[]string{
+			strconv.Itoa(quota),
+			strconv.Itoa(expireSec),
+			slidingLogMember(),
+			strconv.Itoa(cost),
+		},
+	).Int64Slice()
+}
+
+// SetQuotaFull set a permit over quota.
+func (p *PeriodSlidingLogStore) SetQuotaFull(ctx context.Context, key string, quota, expireSec int) error {
+	return p.store.Eval(ctx,
+		redisScript.PeriodSlidingLogLimitSetQuotaFullScript,
+		[]string{
+			key,
+		},
+		[]string{
+			strconv.Itoa(quota),
+			strconv.Itoa(expireSec),
+			slidingLogMember(),
+		},
+	).Err()
+}
+
+// Del delete a permit
+func (p *PeriodSlidingLogStore) Del(ctx context.Context, key string) error {
+	return p.store.Del(ctx, key).Err()
+}
+
+// GetRunValue get run value
+// Exist: false if key not exist.
+// Count: current count in the window
+// TTL: not set expire time, t = -1
+func (p *PeriodSlidingLogStore) GetRunValue(ctx context.Context, key string) ([]int64, error) {
+	return p.store.Eval(ctx,
+		redisScript.PeriodSlidingLogLimitRunValueScript,
+		[]string{
+			key,
+		},
+	).Int64Slice()
+}
+
+// slidingLogMember returns a unique member of the sorted set,
+// the score is the expire time of the permit, which is read from the redis server clock.
+func slidingLogMember() string {
+	return strconv.FormatInt(time.Now().UnixMicro(), 10) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+}
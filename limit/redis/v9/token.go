@@ -3,124 +3,70 @@ package v9
 import (
 	"context"
 	"fmt"
-	"log"
 	"strconv"
-	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
-	xrate "golang.org/x/time/rate"
 
+	"github.com/things-go/limiter/limit"
 	redisScript "github.com/things-go/limiter/limit/redis"
 )
 
-// TokenLimit controls how frequently events are allowed to happen with in one second.
-type TokenLimit struct {
-	rate           int
-	burst          int
-	store          *redis.Client
-	tokenKey       string
-	timestampKey   string
-	rescueLock     sync.Mutex
-	isRedisAlive   uint32
-	rescueLimiter  *xrate.Limiter
-	monitorStarted bool
-}
+var _ limit.TokenStorage = (*TokenStore)(nil)
+var _ limit.HealthCheckKeyer = (*TokenStore)(nil)
+var _ limit.LimitToken = (*limit.TokenLimit[*TokenStore])(nil)
 
-// NewTokenLimit returns a new TokenLimit that allows events up to rate and permits
-// bursts of at most burst tokens.
-func NewTokenLimit(rate, burst int, key string, store *redis.Client) *TokenLimit {
-	return &TokenLimit{
-		rate:          rate,
-		burst:         burst,
-		store:         store,
-		tokenKey:      fmt.Sprintf(redisScript.TokenLimitTokenFormat, key),
-		timestampKey:  fmt.Sprintf(redisScript.TokenLimitTimestampFormat, key),
-		isRedisAlive:  1,
-		rescueLimiter: xrate.NewLimiter(xrate.Every(time.Second/time.Duration(rate)), burst),
-	}
+// A TokenStore is a token bucket storage in redis.
+type TokenStore struct {
+	store *redis.Client
 }
 
-// Allow is shorthand for AllowN(time.Now(), 1).
-func (t *TokenLimit) Allow() bool {
-	return t.AllowN(time.Now(), 1)
+// NewTokenStore returns a TokenStore with given parameters.
+func NewTokenStore(store *redis.Client) *TokenStore {
+	return &TokenStore{
+		store: store,
+	}
 }
 
-// AllowN reports whether n events may happen at time now.
-// Use this method if you intend to drop / skip events that exceed the rate.
-// Otherwise, use Reserve or Wait.
-func (t *TokenLimit) AllowN(now time.Time, n int) bool {
-	return t.reserveN(now, n)
+// NewTokenLimit returns a new TokenLimit that allows events up to rate and permits
+// bursts of at most burst tokens, which stored in redis.
+func NewTokenLimit(rate float64, burst int, key string, store *redis.Client, opts ...limit.TokenLimitOption) *limit.TokenLimit[*TokenStore] {
+	return limit.NewTokenLimit(rate, burst, key, NewTokenStore(store), opts...)
 }
 
-func (t *TokenLimit) reserveN(now time.Time, n int) bool {
-	if atomic.LoadUint32(&t.isRedisAlive) == 0 {
-		return t.rescueLimiter.AllowN(now, n)
-	}
-
-	resp, err := t.store.Eval(context.Background(), redisScript.TokenLimitScript,
-		[]string{t.tokenKey, t.timestampKey},
+// Take takes n tokens at now, if the tokens are not enough, reserve them when
+// the wait time is not greater than maxWait, maxWait < 0 means no limit.
+// rate is the number of tokens generated per second, it may be fractional.
+// it returns [allowed, wait(microsecond), remaining tokens].
+func (t *TokenStore) Take(ctx context.Context, key string, rate float64, burst int, now time.Time, n int, maxWait time.Duration) ([]int64, error) {
+	return t.store.Eval(ctx, redisScript.TokenLimitScript,
 		[]string{
-			strconv.Itoa(t.rate),
-			strconv.Itoa(t.burst),
-			strconv.FormatInt(now.Unix(), 10),
+			fmt.Sprintf(redisScript.TokenLimitTokenFormat, key),
+			fmt.Sprintf(redisScript.TokenLimitTimestampFormat, key),
+		},
+		[]string{
+			strconv.FormatFloat(rate, 'g', -1, 64),
+			strconv.Itoa(burst),
+			strconv.FormatInt(now.UnixMicro(), 10),
 			strconv.Itoa(n),
-		}).Result()
-	// redis allowed == false
-	// Lua boolean false -> r Nil bulk reply
-	if err == redis.Nil {
-		return false
-	}
-	if err != nil {
-		log.Printf("fail to use rate limiter: %s, use in-process limiter for rescue", err)
-		t.startMonitor()
-		return t.rescueLimiter.AllowN(now, n)
-	}
-
-	code, ok := resp.(int64)
-	if !ok {
-		log.Printf("fail to eval redis script: %v, use in-process limiter for rescue", resp)
-		t.startMonitor()
-		return t.rescueLimiter.AllowN(now, n)
-	}
-
-	// redis allowed == true
-	// Lua boolean true -> r integer reply with value of 1
-	return code == 1
+			maxWaitArg(maxWait),
+		}).Int64Slice()
 }
 
-func (t *TokenLimit) startMonitor() {
-	t.rescueLock.Lock()
-	defer t.rescueLock.Unlock()
-
-	if t.monitorStarted {
-		return
+// maxWaitArg returns max wait in microseconds, -1 means no limit.
+func maxWaitArg(maxWait time.Duration) string {
+	if maxWait < 0 {
+		return "-1"
 	}
-
-	t.monitorStarted = true
-	atomic.StoreUint32(&t.isRedisAlive, 0)
-
-	go t.waitForRedis()
+	return strconv.FormatInt(maxWait.Microseconds(), 10)
 }
 
-func (t *TokenLimit) waitForRedis() {
-	ticker := time.NewTicker(redisScript.TokenLimitPingInterval)
-	defer func() {
-		ticker.Stop()
-		t.rescueLock.Lock()
-		t.monitorStarted = false
-		t.rescueLock.Unlock()
-	}()
+// Ping checks whether the redis is available.
+func (t *TokenStore) Ping(ctx context.Context) error {
+	return t.store.Ping(ctx).Err()
+}
 
-	for range ticker.C {
-		v, err := t.store.Ping(context.Background()).Result()
-		if err != nil {
-			continue
-		}
-		if v == "PONG" {
-			atomic.StoreUint32(&t.isRedisAlive, 1)
-			return
-		}
-	}
+// HealthCheckKey the TokenStores of the same redis client share one limit.HealthChecker.
+func (t *TokenStore) HealthCheckKey() any {
+	return t.store
 }
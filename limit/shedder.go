@@ -0,0 +1,303 @@
+package limit
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// shedderCoolOff keep shedding within the duration after the last drop, even if the cpu usage is low.
+	shedderCoolOff = time.Second
+	// shedderCPUDecay the decay of the cpu usage moving average.
+	shedderCPUDecay = 0.95
+	// shedderFlyingDecay the decay of the in-flight moving average.
+	shedderFlyingDecay = 0.9
+)
+
+// CPUSampler returns the cpu usage in [0, 1] since the last call.
+// NOTE: it is called by a single goroutine, need not be safe for concurrent use.
+type CPUSampler func() float64
+
+// Shedder is a BBR like adaptive load shedder, when the cpu usage is above the threshold,
+// it rejects the requests if the in-flight requests exceed the estimated capacity:
+//
+//	maxFlight = maxPass * minRT
+//
+// maxPass is the max passed requests per second, minRT is the min average latency over the window.
+type Shedder struct {
+	window         time.Duration
+	buckets        int
+	cpuThreshold   float64
+	sampler        CPUSampler
+	sampleInterval time.Duration
+	now            func() time.Time
+
+	passes    *rollingWindow
+	rts       *rollingWindow
+	flying    atomic.Int64
+	mu        sync.Mutex
+	avgFlying float64
+	cpu       atomic.Uint64 // float64 bits
+	dropTime  atomic.Int64  // unix nano of the last drop
+
+	// borrowMu guards borrowed.
+	borrowMu sync.Mutex
+	// borrowed the start time of the requests borrowed by TryBorrow, in borrow order.
+	borrowed []time.Time
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewShedder returns a Shedder, it samples the cpu usage in background, use Close to stop it.
+func NewShedder(opts ...ShedderOption) *Shedder {
+	s := &Shedder{
+		window:         5 * time.Second,
+		buckets:        50,
+		cpuThreshold:   0.8,
+		sampleInterval: 250 * time.Millisecond,
+		now:            time.Now,
+		done:           make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.sampler == nil {
+		s.sampler = NewCPUSampler()
+	}
+	interval := s.window / time.Duration(s.buckets)
+	s.passes = newRollingWindow(s.buckets, interval, s.now)
+	s.rts = newRollingWindow(s.buckets, interval, s.now)
+	go s.sampleLoop()
+	return s
+}
+
+// Close stop sampling the cpu usage.
+func (s *Shedder) Close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// TryBorrow tries to borrow an element in non-blocking mode, the same as Limit.TryBorrow,
+// so it can be swapped in for a Limit. It reports false if the request should be dropped.
+// The element must be returned with Return when the request completed.
+// NOTE: Return pairs with the earliest in-flight TryBorrow, so the latency is approximate
+// when the requests complete out of order, use TryBorrowToken for the exact latency.
+func (s *Shedder) TryBorrow() bool {
+	token, ok := s.TryBorrowToken()
+	if !ok {
+		return false
+	}
+	s.borrowMu.Lock()
+	s.borrowed = append(s.borrowed, token.start)
+	s.borrowMu.Unlock()
+	return true
+}
+
+// Return returns the element borrowed by TryBorrow, the request is counted as passed,
+// returns ErrLimitReturn only if returned more than borrowed.
+func (s *Shedder) Return() error {
+	s.borrowMu.Lock()
+	if len(s.borrowed) == 0 {
+		s.borrowMu.Unlock()
+		return ErrLimitReturn
+	}
+	start := s.borrowed[0]
+	s.borrowed = s.borrowed[1:]
+	s.borrowMu.Unlock()
+	s.pass(start)
+	return nil
+}
+
+// TryBorrowToken tries to borrow a token in non-blocking mode, reports false if the request should be dropped.
+// The token must be returned with ShedderToken.Return or ShedderToken.Fail when the request completed.
+func (s *Shedder) TryBorrowToken() (*ShedderToken, bool) {
+	if s.shouldDrop() {
+		s.dropTime.Store(s.now().UnixNano())
+		return nil, false
+	}
+	s.flying.Add(1)
+	return &ShedderToken{s: s, start: s.now()}, true
+}
+
+// CPU returns the moving average of the cpu usage in [0, 1].
+func (s *Shedder) CPU() float64 {
+	return math.Float64frombits(s.cpu.Load())
+}
+
+// InFlight returns the number of in-flight requests.
+func (s *Shedder) InFlight() int {
+	return int(s.flying.Load())
+}
+
+func (s *Shedder) shouldDrop() bool {
+	if s.CPU() < s.cpuThreshold && !s.stillHot() {
+		return false
+	}
+	maxFlight := s.maxFlight()
+	s.mu.Lock()
+	avgFlying := s.avgFlying
+	s.mu.Unlock()
+	return float64(s.flying.Load()) > maxFlight && avgFlying > maxFlight
+}
+
+func (s *Shedder) stillHot() bool {
+	dropTime := s.dropTime.Load()
+	return dropTime != 0 && s.now().UnixNano()-dropTime < int64(shedderCoolOff)
+}
+
+// maxFlight returns the estimated capacity, maxPass * minRT.
+func (s *Shedder) maxFlight() float64 {
+	maxPass := 1.0
+	s.passes.reduce(func(b *rollingBucket) {
+		maxPass = math.Max(maxPass, b.sum)
+	})
+	minRT := math.MaxFloat64
+	s.rts.reduce(func(b *rollingBucket) {
+		if b.count > 0 {
+			minRT = math.Min(minRT, b.sum/float64(b.count))
+		}
+	})
+	if minRT == math.MaxFloat64 {
+		minRT = 1
+	}
+	bucketsPerSecond := float64(time.Second) / float64(s.window/time.Duration(s.buckets))
+	// minRT in millisecond.
+	return math.Max(1, maxPass*bucketsPerSecond*math.Ceil(minRT)/1e3)
+}
+
+// pass counts the request started at start as passed.
+func (s *Shedder) pass(start time.Time) {
+	rt := float64(s.now().Sub(start)) / float64(time.Millisecond)
+	s.returnFlying()
+	s.rts.add(rt)
+	s.passes.add(1)
+}
+
+func (s *Shedder) returnFlying() {
+	flying := s.flying.Add(-1)
+	s.mu.Lock()
+	s.avgFlying = s.avgFlying*shedderFlyingDecay + float64(flying)*(1-shedderFlyingDecay)
+	s.mu.Unlock()
+}
+
+func (s *Shedder) sampleLoop() {
+	ticker := time.NewTicker(s.sampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.sampleCPU()
+		}
+	}
+}
+
+func (s *Shedder) sampleCPU() {
+	usage := math.Min(math.Max(s.sampler(), 0), 1)
+	usage = s.CPU()*shedderCPUDecay + usage*(1-shedderCPUDecay)
+	s.cpu.Store(math.Float64bits(usage))
+}
+
+func (s *Shedder) setWindow(window time.Duration, buckets int) {
+	if buckets > 0 && window/time.Duration(buckets) > 0 {
+		s.window = window
+		s.buckets = buckets
+	}
+}
+
+func (s *Shedder) setCPUThreshold(v float64) {
+	if v > 0 && v <= 1 {
+		s.cpuThreshold = v
+	}
+}
+
+func (s *Shedder) setCPUSampler(sampler CPUSampler, interval time.Duration) {
+	if sampler != nil {
+		s.sampler = sampler
+	}
+	if interval > 0 {
+		s.sampleInterval = interval
+	}
+}
+
+// ShedderToken is the token borrowed from a Shedder by TryBorrowToken.
+type ShedderToken struct {
+	s     *Shedder
+	start time.Time
+	done  atomic.Bool
+}
+
+// Return returns the token when the request succeeded, the request is counted as passed,
+// and its latency is used to estimate the capacity.
+func (t *ShedderToken) Return() {
+	if t.done.CompareAndSwap(false, true) {
+		t.s.pass(t.start)
+	}
+}
+
+// Fail returns the token when the request failed, the request is not counted as passed.
+func (t *ShedderToken) Fail() {
+	if t.done.CompareAndSwap(false, true) {
+		t.s.returnFlying()
+	}
+}
+
+type rollingBucket struct {
+	sum   float64
+	count int64
+}
+
+// rollingWindow is a sliding window divided into buckets.
+type rollingWindow struct {
+	mu       sync.Mutex
+	interval time.Duration
+	buckets  []rollingBucket
+	offset   int
+	lastTime time.Time // start time of the current bucket
+	now      func() time.Time
+}
+
+func newRollingWindow(size int, interval time.Duration, now func() time.Time) *rollingWindow {
+	return &rollingWindow{
+		interval: interval,
+		buckets:  make([]rollingBucket, size),
+		lastTime: now(),
+		now:      now,
+	}
+}
+
+func (w *rollingWindow) add(v float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.updateOffset()
+	b := &w.buckets[w.offset]
+	b.sum += v
+	b.count++
+}
+
+// reduce iterates the completed buckets, the current one is ignored.
+func (w *rollingWindow) reduce(fn func(b *rollingBucket)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.updateOffset()
+	for i := 1; i < len(w.buckets); i++ {
+		fn(&w.buckets[(w.offset+i)%len(w.buckets)])
+	}
+}
+
+// updateOffset NOTE: the caller must hold the lock.
+func (w *rollingWindow) updateOffset() {
+	span := int(w.now().Sub(w.lastTime) / w.interval)
+	if span <= 0 {
+		return
+	}
+	size := len(w.buckets)
+	for i := 1; i <= min(span, size); i++ {
+		w.buckets[(w.offset+i)%size] = rollingBucket{}
+	}
+	w.offset = (w.offset + span) % size
+	w.lastTime = w.lastTime.Add(time.Duration(span) * w.interval)
+}
@@ -0,0 +1,40 @@
+package limit
+
+import (
+	"time"
+)
+
+// ShedderOptionSetter option setter for Shedder
+type ShedderOptionSetter interface {
+	setWindow(window time.Duration, buckets int)
+	setCPUThreshold(v float64)
+	setCPUSampler(s CPUSampler, interval time.Duration)
+}
+
+// ShedderOption defines the method to customize a Shedder.
+type ShedderOption func(s ShedderOptionSetter)
+
+// WithShedderWindow set the statistic window of the passed requests and the latency,
+// the window is divided into buckets.
+// default: 5s, 50 buckets
+func WithShedderWindow(window time.Duration, buckets int) ShedderOption {
+	return func(s ShedderOptionSetter) {
+		s.setWindow(window, buckets)
+	}
+}
+
+// WithCPUThreshold set the cpu usage in (0, 1] above which the system is treated as overloaded.
+// default: 0.8
+func WithCPUThreshold(v float64) ShedderOption {
+	return func(s ShedderOptionSetter) {
+		s.setCPUThreshold(v)
+	}
+}
+
+// WithCPUSampler set the cpu sampler and the sample interval.
+// default: NewCPUSampler(), 250ms
+func WithCPUSampler(sampler CPUSampler, interval time.Duration) ShedderOption {
+	return func(s ShedderOptionSetter) {
+		s.setCPUSampler(sampler, interval)
+	}
+}
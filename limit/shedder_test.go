@@ -0,0 +1,151 @@
+package limit
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestShedder(clock *fakeClock, opts ...ShedderOption) *Shedder {
+	s := NewShedder(opts...)
+	s.now = clock.now
+	s.passes = newRollingWindow(s.buckets, s.window/time.Duration(s.buckets), clock.now)
+	s.rts = newRollingWindow(s.buckets, s.window/time.Duration(s.buckets), clock.now)
+	return s
+}
+
+func TestShedder(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	s := newTestShedder(clock,
+		WithShedderWindow(time.Second, 10),
+		WithCPUSampler(func() float64 { return 0 }, time.Hour),
+	)
+	defer s.Close()
+
+	// 10 passed requests per bucket with 50ms latency, maxFlight = 10 * 10 * 50ms = 5
+	for i := 0; i < 5; i++ {
+		tokens := make([]*ShedderToken, 0, 10)
+		for j := 0; j < 10; j++ {
+			token, ok := s.TryBorrowToken()
+			require.True(t, ok)
+			tokens = append(tokens, token)
+		}
+		clock.advance(50 * time.Millisecond)
+		for _, token := range tokens {
+			token.Return()
+		}
+		clock.advance(50 * time.Millisecond)
+	}
+	assert.Equal(t, 5.0, s.maxFlight())
+
+	// cpu is low, never drop.
+	tokens := make([]*ShedderToken, 0, 30)
+	for i := 0; i < 30; i++ {
+		token, ok := s.TryBorrowToken()
+		require.True(t, ok)
+		tokens = append(tokens, token)
+	}
+	for _, token := range tokens[:10] {
+		token.Fail()
+		token.Fail() // no effect
+	}
+	assert.Equal(t, 20, s.InFlight())
+
+	// overloaded, the in-flight requests exceed the capacity.
+	s.cpu.Store(math.Float64bits(0.9))
+	_, ok := s.TryBorrowToken()
+	assert.False(t, ok)
+
+	// keep shedding within the cool-off duration.
+	s.cpu.Store(math.Float64bits(0))
+	_, ok = s.TryBorrowToken()
+	assert.False(t, ok)
+
+	clock.advance(shedderCoolOff)
+	_, ok = s.TryBorrowToken()
+	assert.True(t, ok)
+}
+
+func TestShedder_TryBorrow(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	s := newTestShedder(clock,
+		WithShedderWindow(time.Second, 10),
+		WithCPUSampler(func() float64 { return 0 }, time.Hour),
+	)
+	defer s.Close()
+
+	// the same shape as Limit: 10 passed requests per bucket with 50ms latency, maxFlight = 5
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 10; j++ {
+			require.True(t, s.TryBorrow())
+		}
+		clock.advance(50 * time.Millisecond)
+		for j := 0; j < 10; j++ {
+			require.NoError(t, s.Return())
+		}
+		clock.advance(50 * time.Millisecond)
+	}
+	assert.Equal(t, 5.0, s.maxFlight())
+	assert.Zero(t, s.InFlight())
+	assert.ErrorIs(t, s.Return(), ErrLimitReturn)
+
+	for i := 0; i < 30; i++ {
+		require.True(t, s.TryBorrow())
+	}
+	for i := 0; i < 10; i++ {
+		require.NoError(t, s.Return())
+	}
+	s.cpu.Store(math.Float64bits(0.9))
+	assert.False(t, s.TryBorrow())
+	assert.Equal(t, 20, s.InFlight())
+}
+
+func TestShedder_SampleCPU(t *testing.T) {
+	s := NewShedder(WithCPUSampler(func() float64 { return 2 }, time.Hour))
+	defer s.Close()
+
+	for i := 0; i < 100; i++ {
+		s.sampleCPU()
+	}
+	assert.InDelta(t, 1.0, s.CPU(), 0.01)
+}
+
+func TestRollingWindow(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	w := newRollingWindow(3, 100*time.Millisecond, clock.now)
+	sum := func() (v float64) {
+		w.reduce(func(b *rollingBucket) { v += b.sum })
+		return v
+	}
+
+	w.add(1)
+	// the current bucket is ignored.
+	assert.Zero(t, sum())
+	clock.advance(100 * time.Millisecond)
+	w.add(2)
+	assert.Equal(t, 1.0, sum())
+	clock.advance(100 * time.Millisecond)
+	assert.Equal(t, 3.0, sum())
+	clock.advance(100 * time.Millisecond)
+	assert.Equal(t, 2.0, sum())
+	clock.advance(time.Second)
+	assert.Zero(t, sum())
+}
+
+func TestNewCPUSampler(t *testing.T) {
+	sampler := NewCPUSampler()
+	for i := 0; i < 3; i++ {
+		v := sampler()
+		assert.True(t, v >= 0 && v <= 1, v)
+	}
+}
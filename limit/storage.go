@@ -2,6 +2,7 @@ package limit
 
 import (
 	"context"
+	"time"
 )
 
 type PeriodFailureStorage interface {
@@ -12,8 +13,66 @@ type PeriodFailureStorage interface {
 }
 
 type PeriodStorage interface {
-	Take(ctx context.Context, key string, quota, expireSec int) (int64, error)
+	// Take requests cost permits, it refuses without consuming if the cost exceeds the remaining quota.
+	// it returns [state, remaining, resetAfter(millisecond), retryAfter(millisecond)],
+	// resetAfter is 0 if the key not exist, -1 if the key never expire, retryAfter is -1 if allowed or never allowed.
+	Take(ctx context.Context, key string, quota, expireSec, cost int) ([]int64, error)
 	SetQuotaFull(ctx context.Context, key string, quota, expireSec int) error
 	Del(ctx context.Context, key string) error
 	GetRunValue(ctx context.Context, key string) ([]int64, error)
 }
+
+// PeriodRefundStorage is implemented by the PeriodStorage which can give back the permits taken by Take,
+// it is required by PeriodLimit.Refund and the compensation of CompositeLimit.
+type PeriodRefundStorage interface {
+	// Refund gives back cost permits taken by Take, used for compensation.
+	Refund(ctx context.Context, key string, cost int) error
+}
+
+type GCRAStorage interface {
+	// Take requests n permits at the current time of the storage,
+	// it returns [allowed, remaining, retryAfter(microsecond), resetAfter(microsecond)].
+	Take(ctx context.Context, key string, burst int, emissionInterval time.Duration, n int) ([]int64, error)
+	Del(ctx context.Context, key string) error
+}
+
+type TokenStorage interface {
+	// Take takes n tokens at now, if the tokens are not enough, reserve them when
+	// the wait time is not greater than maxWait, maxWait < 0 means no limit.
+	// rate is the number of tokens generated per second, it may be fractional.
+	// it returns [allowed, wait(microsecond), remaining tokens].
+	Take(ctx context.Context, key string, rate float64, burst int, now time.Time, n int, maxWait time.Duration) ([]int64, error)
+	// Ping checks whether the storage is available.
+	Ping(ctx context.Context) error
+}
+
+type ConcurrencyStorage interface {
+	// Acquire acquires a lease which expires after ttl, if the number of alive leases less than limit.
+	Acquire(ctx context.Context, key, lease string, limit int, ttl time.Duration) (bool, error)
+	// Refresh extends the alive lease, it reports false if the lease not exist or expired.
+	Refresh(ctx context.Context, key, lease string, ttl time.Duration) (bool, error)
+	// Release releases the lease.
+	Release(ctx context.Context, key, lease string) error
+	// Count returns the number of alive leases.
+	Count(ctx context.Context, key string) (int64, error)
+}
+
+// PeriodBatchStorage is implemented by the PeriodStorage which can take permits of many keys atomically.
+type PeriodBatchStorage interface {
+	// BatchKey returns the identity of the underlying storage, the keys of the storages
+	// with the same identity can be taken in one batch.
+	BatchKey() any
+	// TakeBatch requests the costs of all keys atomically, it consumes all or none.
+	// it returns the same as MultiPeriodStorage.Take.
+	TakeBatch(ctx context.Context, keys []string, quotas, expireSecs, costs []int) ([]int64, error)
+}
+
+type MultiPeriodStorage interface {
+	// Take requests cost permits in all windows atomically, it consumes all or none.
+	// quotas and expireSecs are the quota and the expire seconds of each window key.
+	// it returns [state, blocked window index(-1 if none), then per window:
+	// state, remaining, resetAfter(millisecond), retryAfter(millisecond)].
+	Take(ctx context.Context, keys []string, quotas, expireSecs []int, cost int) ([]int64, error)
+	// Del delete the window keys.
+	Del(ctx context.Context, keys ...string) error
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/things-go/limiter/limit"
+)
+
+func TestCompositeLimit_Take[S limit.PeriodStorage](t *testing.T, store S) {
+	ipLimit := limit.NewPeriodLimit(
+		store,
+		limit.WithKeyPrefix("limit:composite:ip"),
+		limit.WithPeriod(time.Minute),
+		limit.WithQuota(3),
+	)
+	accountLimit := limit.NewPeriodLimit(
+		store,
+		limit.WithKeyPrefix("limit:composite:account"),
+		limit.WithPeriod(time.Minute),
+		limit.WithQuota(2),
+	)
+	login := func(ip, account string) limit.CompositeLimit {
+		return limit.CompositeLimit{
+			{Driver: ipLimit, Key: ip},
+			{Driver: accountLimit, Key: account},
+		}
+	}
+	ipCount := func() int64 {
+		rv, err := ipLimit.GetRunValue(context.Background(), "1.1.1.1")
+		require.NoError(t, err)
+		return rv.Count
+	}
+
+	r, err := login("1.1.1.1", "alice").Take(context.Background())
+	require.NoError(t, err)
+	assert.True(t, r.State.IsAllowed())
+	assert.Equal(t, -1, r.Blocked)
+	require.Len(t, r.Members, 2)
+	assert.Equal(t, 2, r.Members[0].Remaining)
+	assert.Equal(t, 1, r.Members[1].Remaining)
+
+	r, err = login("1.1.1.1", "alice").Take(context.Background())
+	require.NoError(t, err)
+	assert.True(t, r.State.IsHitQuota())
+
+	// the account blocks, the ip is not consumed.
+	r, err = login("1.1.1.1", "alice").Take(context.Background())
+	require.NoError(t, err)
+	assert.True(t, r.State.IsOverQuota())
+	assert.Equal(t, 1, r.Blocked)
+	assert.True(t, r.Members[1].State.IsOverQuota())
+	assert.Equal(t, int64(2), ipCount())
+
+	r, err = login("1.1.1.1", "bob").Take(context.Background())
+	require.NoError(t, err)
+	assert.True(t, r.State.IsHitQuota())
+
+	// the ip blocks.
+	r, err = login("1.1.1.1", "carol").Take(context.Background())
+	require.NoError(t, err)
+	assert.True(t, r.State.IsOverQuota())
+	assert.Equal(t, 0, r.Blocked)
+	rv, err := accountLimit.GetRunValue(context.Background(), "carol")
+	require.NoError(t, err)
+	assert.Zero(t, rv.Count)
+
+	// a member failed, the taken ones are refunded.
+	require.NoError(t, ipLimit.Del(context.Background(), "1.1.1.1"))
+	_, err = limit.CompositeLimit{
+		{Driver: ipLimit, Key: "1.1.1.1", Cost: 2},
+		{Driver: limit.UnsupportedPeriodLimitDriver{}, Key: "dave"},
+	}.Take(context.Background())
+	assert.ErrorIs(t, err, limit.ErrUnsupportedDriver)
+	assert.Zero(t, ipCount())
+
+	_, err = limit.CompositeLimit{{Driver: ipLimit, Key: "1.1.1.1", Cost: -1}}.Take(context.Background())
+	assert.ErrorIs(t, err, limit.ErrInvalidCost)
+}
+
+// noRefundStorage hides the Refund of the PeriodStorage.
+type noRefundStorage struct {
+	limit.PeriodStorage
+}
+
+func TestCompositeLimit_SameKey[S limit.PeriodStorage](t *testing.T, store S) {
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithKeyPrefix("limit:composite:same"),
+		limit.WithPeriod(time.Minute),
+		limit.WithQuota(5),
+	)
+	same := limit.CompositeLimit{
+		{Driver: l, Key: "first", Cost: 2},
+		{Driver: l, Key: "first", Cost: 2},
+	}
+	count := func() int64 {
+		rv, err := l.GetRunValue(context.Background(), "first")
+		require.NoError(t, err)
+		return rv.Count
+	}
+
+	r, err := same.Take(context.Background())
+	require.NoError(t, err)
+	assert.True(t, r.State.IsAllowed())
+	assert.Equal(t, int64(4), count())
+
+	// the members on the same key are counted together, never overrun the quota.
+	r, err = same.Take(context.Background())
+	require.NoError(t, err)
+	assert.True(t, r.State.IsOverQuota())
+	assert.Equal(t, 0, r.Blocked)
+	assert.Equal(t, int64(4), count())
+
+	r, err = limit.CompositeLimit{
+		{Driver: l, Key: "first"},
+		{Driver: l, Key: "first"},
+	}.Take(context.Background())
+	require.NoError(t, err)
+	assert.True(t, r.State.IsOverQuota())
+	assert.Equal(t, 1, r.Blocked)
+	assert.Equal(t, int64(4), count())
+}
+
+func TestCompositeLimit_RefundUnsupported[S limit.PeriodStorage](t *testing.T, store S) {
+	ipLimit := limit.NewPeriodLimit(
+		store,
+		limit.WithKeyPrefix("limit:composite:refund:ip"),
+		limit.WithPeriod(time.Minute),
+		limit.WithQuota(3),
+	)
+	accountLimit := limit.NewPeriodLimit(
+		noRefundStorage{store},
+		limit.WithKeyPrefix("limit:composite:refund:account"),
+		limit.WithPeriod(time.Minute),
+		limit.WithQuota(2),
+	)
+	assert.True(t, ipLimit.CanRefund())
+	assert.False(t, accountLimit.CanRefund())
+	assert.ErrorIs(t, accountLimit.Refund(context.Background(), "alice", 1), limit.ErrRefundUnsupported)
+
+	// nothing is taken.
+	_, err := limit.CompositeLimit{
+		{Driver: ipLimit, Key: "1.1.1.1"},
+		{Driver: accountLimit, Key: "alice"},
+	}.Take(context.Background())
+	assert.ErrorIs(t, err, limit.ErrRefundUnsupported)
+	rv, err := ipLimit.GetRunValue(context.Background(), "1.1.1.1")
+	require.NoError(t, err)
+	assert.False(t, rv.Exist)
+}
+
+// TestPeriodLimit_RefundUnsupported tests the PeriodStorage which is not a PeriodRefundStorage.
+func TestPeriodLimit_RefundUnsupported[S limit.PeriodStorage](t *testing.T, store S) {
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithKeyPrefix("limit:refund:unsupported"),
+		limit.WithPeriod(time.Minute),
+		limit.WithQuota(3),
+	)
+	assert.False(t, l.CanRefund())
+	assert.ErrorIs(t, l.Refund(context.Background(), "first", 1), limit.ErrRefundUnsupported)
+
+	_, err := limit.CompositeLimit{
+		{Driver: l, Key: "first"},
+		{Driver: l, Key: "second"},
+	}.Take(context.Background())
+	assert.ErrorIs(t, err, limit.ErrRefundUnsupported)
+	rv, err := l.GetRunValue(context.Background(), "first")
+	require.NoError(t, err)
+	assert.False(t, rv.Exist)
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/things-go/limiter/limit"
+)
+
+func TestConcurrencyLimit_Acquire[S limit.ConcurrencyStorage](t *testing.T, store S) {
+	const total = 3
+
+	ctx := context.Background()
+	l := limit.NewConcurrencyLimit(store, total, limit.WithConcurrencyKeyPrefix("limit:concurrency"))
+	leases := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		lease, err := l.Acquire(ctx, "first")
+		require.NoError(t, err)
+		assert.NotEmpty(t, lease)
+		leases = append(leases, lease)
+	}
+	_, err := l.Acquire(ctx, "first")
+	assert.ErrorIs(t, err, limit.ErrConcurrencyLimitReached)
+
+	// other key is independent.
+	_, err = l.Acquire(ctx, "second")
+	require.NoError(t, err)
+
+	n, err := l.InFlight(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, total, n)
+
+	require.NoError(t, l.Release(ctx, "first", leases[0]))
+	n, err = l.InFlight(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, total-1, n)
+
+	_, err = l.Acquire(ctx, "first")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "first")
+	assert.ErrorIs(t, err, limit.ErrConcurrencyLimitReached)
+}
+
+func TestConcurrencyLimit_Expire[S limit.ConcurrencyStorage](t *testing.T, store S) {
+	ctx := context.Background()
+	l := limit.NewConcurrencyLimit(store, 1, limit.WithLeaseTTL(100*time.Millisecond))
+
+	lease, err := l.Acquire(ctx, "first")
+	require.NoError(t, err)
+	_, err = l.Acquire(ctx, "first")
+	assert.ErrorIs(t, err, limit.ErrConcurrencyLimitReached)
+
+	// refresh keep the lease alive.
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, l.Refresh(ctx, "first", lease))
+	time.Sleep(60 * time.Millisecond)
+	_, err = l.Acquire(ctx, "first")
+	assert.ErrorIs(t, err, limit.ErrConcurrencyLimitReached)
+
+	// the holder crashed, the lease expires automatically.
+	time.Sleep(60 * time.Millisecond)
+	n, err := l.InFlight(ctx, "first")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.ErrorIs(t, l.Refresh(ctx, "first", lease), limit.ErrLeaseNotFound)
+
+	_, err = l.Acquire(ctx, "first")
+	require.NoError(t, err)
+}
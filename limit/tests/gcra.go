@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/things-go/limiter/limit"
+)
+
+const (
+	gcraRate  = 10
+	gcraBurst = 5
+)
+
+func TestGCRALimit_Allow[S limit.GCRAStorage](t *testing.T, store S) {
+	l := limit.NewGCRALimit(
+		store,
+		gcraRate,
+		time.Second,
+		limit.WithGCRAKeyPrefix("limit:gcra"),
+		limit.WithGCRABurst(gcraBurst),
+	)
+	// the requests are taken at the storage clock, allow the time passed between them.
+	const delta = float64(20 * time.Millisecond)
+	for i := 0; i < gcraBurst; i++ {
+		r, err := l.AllowN(context.Background(), "first", 1)
+		require.NoError(t, err)
+		assert.True(t, r.Allowed)
+		assert.Equal(t, gcraBurst, r.Limit)
+		assert.Equal(t, gcraBurst-1-i, r.Remaining)
+		assert.Equal(t, time.Duration(-1), r.RetryAfter)
+		assert.InDelta(t, time.Duration(i+1)*100*time.Millisecond, r.ResetAfter, delta)
+	}
+
+	r, err := l.AllowN(context.Background(), "first", 1)
+	require.NoError(t, err)
+	assert.False(t, r.Allowed)
+	assert.Zero(t, r.Remaining)
+	assert.InDelta(t, 100*time.Millisecond, r.RetryAfter, delta)
+	assert.InDelta(t, 500*time.Millisecond, r.ResetAfter, delta)
+
+	// a request emitted after the emission interval.
+	time.Sleep(100 * time.Millisecond)
+	r, err = l.AllowN(context.Background(), "first", 1)
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Zero(t, r.Remaining)
+
+	// never allowed, more than the burst.
+	r, err = l.AllowN(context.Background(), "second", gcraBurst+1)
+	require.NoError(t, err)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, time.Duration(-1), r.RetryAfter)
+}
+
+func TestGCRALimit_Del[S limit.GCRAStorage](t *testing.T, store S) {
+	l := limit.NewGCRALimit(store, gcraRate, time.Second, limit.WithGCRABurst(gcraBurst))
+
+	r, err := l.AllowN(context.Background(), "first", gcraBurst)
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	r, err = l.Allow(context.Background(), "first")
+	require.NoError(t, err)
+	assert.False(t, r.Allowed)
+
+	err = l.Del(context.Background(), "first")
+	require.NoError(t, err)
+
+	r, err = l.Allow(context.Background(), "first")
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, gcraBurst-1, r.Remaining)
+}
+
+func TestGCRALimit_RedisUnavailable[S limit.GCRAStorage](t *testing.T, store S) {
+	l := limit.NewGCRALimit(store, gcraRate, time.Second)
+	r, err := l.Allow(context.Background(), "first")
+	assert.Error(t, err)
+	assert.Nil(t, r)
+}
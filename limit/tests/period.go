@@ -2,10 +2,12 @@ package tests
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/things-go/limiter/limit"
 )
@@ -80,6 +82,178 @@ func TestPeriodLimit_QuotaFull[S limit.PeriodStorage](t *testing.T, store S) {
 	assert.True(t, val.IsHitQuota())
 }
 
+func TestPeriodLimit_TakeN[S limit.PeriodStorage](t *testing.T, store S) {
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithPeriod(seconds),
+		limit.WithQuota(quota),
+	)
+	val, err := l.TakeN(context.Background(), "first", 2)
+	assert.NoError(t, err)
+	assert.True(t, val.IsAllowed())
+	val, err = l.TakeN(context.Background(), "first", 2)
+	assert.NoError(t, err)
+	assert.True(t, val.IsAllowed())
+
+	// refuse without consuming any permit.
+	val, err = l.TakeN(context.Background(), "first", 2)
+	assert.NoError(t, err)
+	assert.True(t, val.IsOverQuota())
+	rv, err := l.GetRunValue(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(quota-1), rv.Count)
+
+	val, err = l.TakeN(context.Background(), "first", 1)
+	assert.NoError(t, err)
+	assert.True(t, val.IsHitQuota())
+
+	// exceeds the quota, never allowed.
+	val, err = l.TakeN(context.Background(), "second", quota+1)
+	assert.NoError(t, err)
+	assert.True(t, val.IsOverQuota())
+
+	_, err = l.TakeN(context.Background(), "first", 0)
+	assert.ErrorIs(t, err, limit.ErrInvalidCost)
+}
+
+func TestPeriodLimit_TakeWithResult[S limit.PeriodStorage](t *testing.T, store S) {
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithPeriod(seconds),
+		limit.WithQuota(quota),
+	)
+	r, err := l.TakeNWithResult(context.Background(), "first", 2)
+	require.NoError(t, err)
+	assert.True(t, r.State.IsAllowed())
+	assert.Equal(t, quota, r.Limit)
+	assert.Equal(t, quota-2, r.Remaining)
+	assert.Greater(t, r.ResetAfter, time.Duration(0))
+	assert.LessOrEqual(t, r.ResetAfter, 2*seconds)
+	assert.Equal(t, time.Duration(-1), r.RetryAfter)
+
+	r, err = l.TakeNWithResult(context.Background(), "first", quota-2)
+	require.NoError(t, err)
+	assert.True(t, r.State.IsHitQuota())
+	assert.Zero(t, r.Remaining)
+	assert.Equal(t, time.Duration(-1), r.RetryAfter)
+
+	r, err = l.TakeWithResult(context.Background(), "first")
+	require.NoError(t, err)
+	assert.True(t, r.State.IsOverQuota())
+	assert.Zero(t, r.Remaining)
+	assert.Greater(t, r.RetryAfter, time.Duration(0))
+	assert.LessOrEqual(t, r.RetryAfter, 2*seconds)
+	assert.LessOrEqual(t, r.RetryAfter, r.ResetAfter)
+
+	// exceeds the quota, never allowed.
+	r, err = l.TakeNWithResult(context.Background(), "second", quota+1)
+	require.NoError(t, err)
+	assert.True(t, r.State.IsOverQuota())
+	assert.Equal(t, quota, r.Remaining)
+	assert.Equal(t, time.Duration(-1), r.RetryAfter)
+}
+
+func TestPeriodLimit_Refund[S limit.PeriodStorage](t *testing.T, store S) {
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithPeriod(seconds),
+		limit.WithQuota(quota),
+	)
+	// key not exist.
+	require.NoError(t, l.Refund(context.Background(), "first", 1))
+
+	val, err := l.TakeN(context.Background(), "first", quota)
+	require.NoError(t, err)
+	assert.True(t, val.IsHitQuota())
+
+	require.NoError(t, l.Refund(context.Background(), "first", 2))
+	val, err = l.TakeN(context.Background(), "first", 2)
+	require.NoError(t, err)
+	assert.True(t, val.IsHitQuota())
+
+	// never below zero.
+	require.NoError(t, l.Refund(context.Background(), "first", quota+1))
+	val, err = l.TakeN(context.Background(), "first", quota)
+	require.NoError(t, err)
+	assert.True(t, val.IsHitQuota())
+
+	assert.ErrorIs(t, l.Refund(context.Background(), "first", 0), limit.ErrInvalidCost)
+}
+
+func TestPeriodLimit_QuotaResolver[S limit.PeriodStorage](t *testing.T, store S) {
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithPeriod(seconds),
+		limit.WithQuota(quota),
+		limit.WithQuotaResolver(func(_ context.Context, key string) (int, time.Duration) {
+			if key == "premium" {
+				return 2 * quota, time.Minute
+			}
+			return 0, 0 // default
+		}),
+	)
+	r, err := l.TakeNWithResult(context.Background(), "premium", quota+1)
+	require.NoError(t, err)
+	assert.True(t, r.State.IsAllowed())
+	assert.Equal(t, 2*quota, r.Limit)
+	assert.Greater(t, r.ResetAfter, seconds)
+
+	r, err = l.TakeNWithResult(context.Background(), "free", quota+1)
+	require.NoError(t, err)
+	assert.True(t, r.State.IsOverQuota())
+	assert.Equal(t, quota, r.Limit)
+}
+
+func TestPeriodLimit_TakeWithCalendarAlign[S limit.PeriodStorage](t *testing.T, store S) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithKeyPrefix("limit:period:calendar"),
+		limit.WithQuota(quota),
+		limit.WithCalendarAlign(limit.CalendarMonth, loc),
+	)
+	now := time.Now()
+	r, err := l.TakeWithResult(context.Background(), "first")
+	require.NoError(t, err)
+	assert.True(t, r.State.IsAllowed())
+	assert.InDelta(t, limit.CalendarMonth.Next(now, loc).Sub(now), r.ResetAfter, float64(2*time.Second))
+}
+
+func TestPeriodLimit_Update[S limit.PeriodStorage](t *testing.T, store S) {
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithKeyPrefix("limit:period:update"),
+		limit.WithPeriod(seconds),
+		limit.WithQuota(quota),
+	)
+	sts, err := l.TakeN(context.Background(), "first", quota)
+	require.NoError(t, err)
+	assert.True(t, sts.IsHitQuota())
+
+	l.Update(limit.WithQuota(2 * quota))
+	r, err := l.TakeNWithResult(context.Background(), "first", quota)
+	require.NoError(t, err)
+	assert.True(t, r.State.IsHitQuota())
+	assert.Equal(t, 2*quota, r.Limit)
+	assert.Zero(t, r.Remaining)
+
+	// in-flight calls keep a consistent view while updating.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := l.Take(context.Background(), "second")
+			assert.NoError(t, err)
+		}()
+		go func(i int) {
+			defer wg.Done()
+			l.Update(limit.WithQuota(quota + i))
+		}(i)
+	}
+	wg.Wait()
+}
+
 func TestPeriodLimit_SetQuotaFull[S limit.PeriodStorage](t *testing.T, store S) {
 	l := limit.NewPeriodLimit(store)
 
@@ -138,3 +312,69 @@ func TestPeriodLimit_Del[S limit.PeriodStorage](t *testing.T, store S) {
 	assert.NoError(t, err)
 	assert.True(t, val.IsAllowed())
 }
+
+// TestPeriodLimit_TakeSliding the permits slide out of the window one by one,
+// not reset all at once at the window boundary.
+func TestPeriodLimit_TakeSliding[S limit.PeriodStorage](t *testing.T, store S) {
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithPeriod(seconds),
+		limit.WithQuota(2),
+	)
+	val, err := l.Take(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.True(t, val.IsAllowed())
+
+	time.Sleep(seconds / 2)
+
+	val, err = l.Take(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.True(t, val.IsHitQuota())
+	val, err = l.Take(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.True(t, val.IsOverQuota())
+
+	// the first permit slides out of the window, the second one is still in the window.
+	time.Sleep(seconds/2 + seconds/10)
+
+	val, err = l.Take(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.True(t, val.IsHitQuota())
+	val, err = l.Take(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.True(t, val.IsOverQuota())
+}
+
+// TestPeriodLimit_TakeWeighted the previous window count is weighted by
+// how much of it overlaps the sliding window.
+func TestPeriodLimit_TakeWeighted[S limit.PeriodStorage](t *testing.T, store S) {
+	const weightedQuota = 10
+
+	l := limit.NewPeriodLimit(
+		store,
+		limit.WithPeriod(seconds),
+		limit.WithQuota(weightedQuota),
+	)
+	// wait for the start of a window.
+	time.Sleep(time.Until(time.Now().Truncate(seconds).Add(seconds + seconds/100)))
+	for i := 0; i < weightedQuota; i++ {
+		_, err := l.Take(context.Background(), "first")
+		assert.NoError(t, err)
+	}
+	val, err := l.Take(context.Background(), "first")
+	assert.NoError(t, err)
+	assert.True(t, val.IsOverQuota())
+
+	// in the middle of next window, about half of the previous window overlaps.
+	time.Sleep(time.Until(time.Now().Truncate(seconds).Add(seconds + seconds/2)))
+	var allowed int
+	for i := 0; i < weightedQuota; i++ {
+		val, err = l.Take(context.Background(), "first")
+		assert.NoError(t, err)
+		if !val.IsOverQuota() {
+			allowed++
+		}
+	}
+	assert.GreaterOrEqual(t, allowed, weightedQuota/2-1)
+	assert.LessOrEqual(t, allowed, weightedQuota/2+1)
+}
@@ -129,6 +129,70 @@ func TestPeriodFailureLimit_Check_Over_Limit_Failure_Time_Then_Success_Always_Ov
 	assert.Equal(t, int64(quota+1), rv.Count)
 }
 
+func TestPeriodFailureLimit_QuotaResolver[S limit.PeriodFailureStorage](t *testing.T, store S) {
+	l := limit.NewPeriodFailureLimit(
+		store,
+		limit.WithPeriod(seconds),
+		limit.WithQuota(1),
+		limit.WithQuotaResolver(func(_ context.Context, key string) (int, time.Duration) {
+			if key == "internal" {
+				return quota, 0
+			}
+			return 0, 0
+		}),
+	)
+	for i := 0; i < quota; i++ {
+		sts, err := l.CheckErr(context.Background(), "internal", errInternal)
+		assert.NoError(t, err)
+		assert.True(t, sts.IsWithinQuota())
+	}
+	sts, err := l.CheckErr(context.Background(), "internal", errInternal)
+	assert.NoError(t, err)
+	assert.True(t, sts.IsOverQuota())
+
+	sts, err = l.CheckErr(context.Background(), "other", errInternal)
+	assert.NoError(t, err)
+	assert.True(t, sts.IsWithinQuota())
+	sts, err = l.CheckErr(context.Background(), "other", errInternal)
+	assert.NoError(t, err)
+	assert.True(t, sts.IsOverQuota())
+}
+
+func TestPeriodFailureLimit_CheckWithCalendarAlign[S limit.PeriodFailureStorage](t *testing.T, store S) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	l := limit.NewPeriodFailureLimit(
+		store,
+		limit.WithQuota(quota),
+		limit.WithCalendarAlign(limit.CalendarDay, loc),
+	)
+	now := time.Now()
+	_, err := l.CheckErr(context.Background(), "calendar", errInternal)
+	assert.NoError(t, err)
+	v, err := l.GetRunValue(context.Background(), "calendar")
+	assert.NoError(t, err)
+	assert.True(t, v.Exist)
+	assert.InDelta(t, limit.CalendarDay.Next(now, loc).Sub(now), v.TTL, float64(2*time.Second))
+}
+
+func TestPeriodFailureLimit_Update[S limit.PeriodFailureStorage](t *testing.T, store S) {
+	l := limit.NewPeriodFailureLimit(
+		store,
+		limit.WithPeriod(seconds),
+		limit.WithQuota(1),
+	)
+	sts, err := l.CheckErr(context.Background(), "update", errInternal)
+	assert.NoError(t, err)
+	assert.True(t, sts.IsWithinQuota())
+	sts, err = l.CheckErr(context.Background(), "update", errInternal)
+	assert.NoError(t, err)
+	assert.True(t, sts.IsOverQuota())
+
+	l.Update(limit.WithQuota(quota))
+	sts, err = l.CheckErr(context.Background(), "update", errInternal)
+	assert.NoError(t, err)
+	assert.True(t, sts.IsWithinQuota())
+}
+
 func TestPeriodFailureLimit_SetQuotaFull[S limit.PeriodFailureStorage](t *testing.T, store S) {
 	l := limit.NewPeriodFailureLimit(store)
 
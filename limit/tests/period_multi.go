@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/things-go/limiter/limit"
+)
+
+func TestMultiPeriodLimit_Take[S limit.MultiPeriodStorage](t *testing.T, store S) {
+	windows := []limit.PeriodWindow{
+		{Period: seconds, Quota: 10},
+		{Period: time.Minute, Quota: 3},
+	}
+	l, err := limit.NewMultiPeriodLimit(store, windows, limit.WithMultiPeriodKeyPrefix("limit:multi"))
+	require.NoError(t, err)
+	// the windows are copied.
+	windows[1].Quota = 100
+
+	r, err := l.TakeN(context.Background(), "first", 2)
+	require.NoError(t, err)
+	assert.True(t, r.State.IsAllowed())
+	assert.Equal(t, -1, r.Blocked)
+	assert.Equal(t, 1, r.Remaining)
+	assert.Equal(t, time.Duration(-1), r.RetryAfter)
+	require.Len(t, r.Windows, 2)
+	assert.Equal(t, 10, r.Windows[0].Limit)
+	assert.Equal(t, 8, r.Windows[0].Remaining)
+	assert.Equal(t, 3, r.Windows[1].Limit)
+	assert.Equal(t, 1, r.Windows[1].Remaining)
+
+	// the second window blocks, the first window is not consumed.
+	r, err = l.TakeN(context.Background(), "first", 2)
+	require.NoError(t, err)
+	assert.True(t, r.State.IsOverQuota())
+	assert.Equal(t, 1, r.Blocked)
+	assert.True(t, r.Windows[0].State.IsAllowed())
+	assert.Equal(t, 8, r.Windows[0].Remaining)
+	assert.True(t, r.Windows[1].State.IsOverQuota())
+	assert.Equal(t, 1, r.Windows[1].Remaining)
+	assert.Greater(t, r.RetryAfter, 59*time.Second)
+	assert.LessOrEqual(t, r.RetryAfter, time.Minute)
+
+	r, err = l.Take(context.Background(), "first")
+	require.NoError(t, err)
+	assert.True(t, r.State.IsHitQuota())
+	assert.Equal(t, 7, r.Windows[0].Remaining)
+	assert.True(t, r.Windows[1].State.IsHitQuota())
+	assert.Zero(t, r.Remaining)
+
+	r, err = l.Take(context.Background(), "first")
+	require.NoError(t, err)
+	assert.True(t, r.State.IsOverQuota())
+	assert.Equal(t, 1, r.Blocked)
+
+	// exceeds the quota, never allowed.
+	r, err = l.TakeN(context.Background(), "second", 11)
+	require.NoError(t, err)
+	assert.True(t, r.State.IsOverQuota())
+	assert.Equal(t, 0, r.Blocked)
+	assert.Equal(t, time.Duration(-1), r.RetryAfter)
+
+	require.NoError(t, l.Del(context.Background(), "first"))
+	r, err = l.Take(context.Background(), "first")
+	require.NoError(t, err)
+	assert.True(t, r.State.IsAllowed())
+
+	_, err = l.TakeN(context.Background(), "first", 0)
+	assert.ErrorIs(t, err, limit.ErrInvalidCost)
+	_, err = limit.NewMultiPeriodLimit(store, nil)
+	assert.ErrorIs(t, err, limit.ErrNoPeriodWindow)
+	for _, windows := range [][]limit.PeriodWindow{
+		{{Period: seconds, Quota: 0}},
+		{{Period: seconds, Quota: -1}},
+		{{Period: 500 * time.Millisecond, Quota: 10}},
+		{{Period: 1500 * time.Millisecond, Quota: 10}},
+		{{Period: time.Minute, Quota: 10}, {Period: 60 * time.Second, Quota: 100}},
+	} {
+		_, err = limit.NewMultiPeriodLimit(store, windows)
+		assert.ErrorIs(t, err, limit.ErrInvalidPeriodWindow, windows)
+	}
+}
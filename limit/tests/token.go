@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	xrate "golang.org/x/time/rate"
+
+	"github.com/things-go/limiter/limit"
+)
+
+const (
+	tokenRate  = 5
+	tokenBurst = 10
+)
+
+func TestTokenLimit_Take[S limit.TokenStorage](t *testing.T, store S) {
+	l := limit.NewTokenLimit(tokenRate, tokenBurst, "tokenlimit", store)
+	var allowed int
+	for i := 0; i < total; i++ {
+		time.Sleep(time.Second / time.Duration(total))
+		if l.Allow() {
+			allowed++
+		}
+	}
+
+	assert.True(t, allowed >= tokenBurst+tokenRate)
+}
+
+func TestTokenLimit_TakeBurst[S limit.TokenStorage](t *testing.T, store S) {
+	l := limit.NewTokenLimit(tokenRate, tokenBurst, "tokenlimit", store)
+	var allowed int
+	for i := 0; i < total; i++ {
+		if l.Allow() {
+			allowed++
+		}
+	}
+
+	assert.True(t, allowed >= tokenBurst)
+}
+
+func TestKeyedTokenLimit_Take[S limit.TokenStorage](t *testing.T, store S) {
+	l := limit.NewKeyedTokenLimit(tokenRate, tokenBurst, store)
+	var first, second int
+	for i := 0; i < total; i++ {
+		if l.Allow(context.Background(), "first") {
+			first++
+		}
+		if l.Allow(context.Background(), "second") {
+			second++
+		}
+	}
+
+	assert.True(t, first >= tokenBurst && first < total)
+	assert.True(t, second >= tokenBurst && second < total)
+}
+
+func TestTokenLimit_Reserve[S limit.TokenStorage](t *testing.T, store S) {
+	l := limit.NewTokenLimit(tokenRate, tokenBurst, "tokenlimit", store)
+	now := time.Now()
+
+	r := l.ReserveN(context.Background(), now, tokenBurst)
+	assert.True(t, r.OK())
+	assert.Zero(t, r.DelayFrom(now))
+
+	r = l.ReserveN(context.Background(), now, tokenRate)
+	assert.True(t, r.OK())
+	assert.Equal(t, time.Second, r.DelayFrom(now))
+
+	r = l.ReserveN(context.Background(), now, tokenBurst+1)
+	assert.False(t, r.OK())
+	assert.Equal(t, xrate.InfDuration, r.DelayFrom(now))
+}
+
+func TestTokenLimit_Wait[S limit.TokenStorage](t *testing.T, store S) {
+	l := limit.NewTokenLimit(tokenRate, tokenBurst, "tokenlimit", store)
+
+	err := l.Wait(context.Background())
+	assert.NoError(t, err)
+
+	err = l.WaitN(context.Background(), tokenBurst+1)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = l.Wait(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the expected wait time exceeds the deadline.
+	assert.True(t, l.AllowN(time.Now(), tokenBurst-1))
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = l.WaitN(ctx, tokenBurst)
+	assert.Error(t, err)
+}
+
+func TestTokenLimit_FractionalRate[S limit.TokenStorage](t *testing.T, store S) {
+	// 10 events per minute
+	l := limit.NewTokenLimit(limit.Every(6*time.Second), 1, "tokenlimit:fractional", store)
+	now := time.Now()
+
+	r := l.ReserveN(context.Background(), now, 1)
+	assert.True(t, r.OK())
+	assert.Zero(t, r.DelayFrom(now))
+	r = l.ReserveN(context.Background(), now.Add(time.Second), 1)
+	assert.True(t, r.OK())
+	assert.InDelta(t, 6*time.Second, r.DelayFrom(now), float64(time.Millisecond))
+
+	// refill with sub-second precision, the ttl should not be zero.
+	tb, err := store.Take(context.Background(), "tokenlimit:precision", 100, 1, now, 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), tb[0])
+	tb, err = store.Take(context.Background(), "tokenlimit:precision", 100, 1, now.Add(5*time.Millisecond), 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), tb[0])
+	assert.Equal(t, int64(5*time.Millisecond/time.Microsecond), tb[1])
+	tb, err = store.Take(context.Background(), "tokenlimit:precision", 100, 1, now.Add(10*time.Millisecond), 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), tb[0])
+}
+
+func TestTokenLimit_TryReserve[S limit.TokenStorage](t *testing.T, store S) {
+	l := limit.NewTokenLimit(tokenRate, tokenBurst, "tokenlimit:try", store)
+	now := time.Now()
+
+	r := l.TryReserveN(context.Background(), now, tokenBurst-2)
+	assert.True(t, r.OK())
+	assert.Equal(t, 2, r.Remaining())
+	assert.Equal(t, time.Duration(-1), r.RetryAfter())
+
+	// refuse without consuming.
+	r = l.TryReserveN(context.Background(), now, 3)
+	assert.False(t, r.OK())
+	assert.Equal(t, 2, r.Remaining())
+	assert.Equal(t, time.Second/tokenRate, r.RetryAfter())
+
+	// never available.
+	r = l.TryReserveN(context.Background(), now, tokenBurst+1)
+	assert.False(t, r.OK())
+	assert.Equal(t, time.Duration(-1), r.RetryAfter())
+
+	kl := limit.NewKeyedTokenLimit(tokenRate, tokenBurst, store)
+	r = kl.TryReserveN(context.Background(), "tokenlimit:try:keyed", now, 1)
+	assert.True(t, r.OK())
+	assert.Equal(t, tokenBurst-1, r.Remaining())
+}
+
+func TestTokenLimit_Update[S limit.TokenStorage](t *testing.T, store S) {
+	l := limit.NewTokenLimit(tokenRate, tokenBurst, "tokenlimit:update", store)
+	now := time.Now()
+
+	l.Update(tokenRate*2, 1)
+	assert.Equal(t, float64(tokenRate*2), l.Limit())
+	assert.Equal(t, 1, l.Burst())
+
+	// the tokens are capped to the new burst.
+	r := l.TryReserveN(context.Background(), now, 2)
+	assert.False(t, r.OK())
+	assert.Equal(t, time.Duration(-1), r.RetryAfter())
+	r = l.TryReserveN(context.Background(), now, 1)
+	assert.True(t, r.OK())
+	assert.Zero(t, r.Remaining())
+	r = l.TryReserveN(context.Background(), now, 1)
+	assert.False(t, r.OK())
+	assert.Equal(t, time.Second/(tokenRate*2), r.RetryAfter())
+}
@@ -1,10 +1,258 @@
 package limit
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
 	"time"
+
+	xrate "golang.org/x/time/rate"
 )
 
+// TokenLimitPingInterval the interval of ping the storage when it is unavailable.
+const TokenLimitPingInterval = time.Millisecond * 100
+
 type LimitToken interface {
 	AllowN(now time.Time, n int) bool
 	Allow() bool
 }
+
+var _ LimitToken = (*TokenLimit[TokenStorage])(nil)
+
+// Every converts a minimum time interval between events to a rate of events per second,
+// such as Every(6*time.Second) means 10 events per minute.
+func Every(interval time.Duration) float64 {
+	if interval <= 0 {
+		return math.MaxFloat64
+	}
+	return 1 / interval.Seconds()
+}
+
+// TokenLimit controls how frequently events are allowed to happen with in one second.
+// if the storage is unavailable, it follows the failure policy, default uses an in-process limiter for rescue.
+type TokenLimit[S TokenStorage] struct {
+	limits        atomic.Pointer[tokenLimits]
+	key           string
+	store         S
+	rescueLimiter *xrate.Limiter
+	opt           *tokenLimitOption
+	*storeHealth
+}
+
+// NewTokenLimit returns a new TokenLimit that allows events up to rate per second and permits
+// bursts of at most burst tokens, rate may be fractional, see Every.
+func NewTokenLimit[S TokenStorage](rate float64, burst int, key string, store S, opts ...TokenLimitOption) *TokenLimit[S] {
+	o := newTokenLimitOption(opts...)
+	t := &TokenLimit[S]{
+		key:           key,
+		store:         store,
+		rescueLimiter: o.newRescueLimiter(rate, burst),
+		opt:           o,
+		storeHealth:   newStoreHealth(store, o),
+	}
+	t.limits.Store(&tokenLimits{rate: rate, burst: burst})
+	return t
+}
+
+// tokenLimits is an immutable snapshot of the rate and burst of a TokenLimit.
+type tokenLimits struct {
+	rate  float64
+	burst int
+}
+
+// Close releases the HealthChecker of the storage, the shared one will be closed
+// when all limiters of the storage closed.
+func (t *TokenLimit[S]) Close() {
+	t.storeHealth.close()
+}
+
+// Allow is shorthand for AllowN(time.Now(), 1).
+func (t *TokenLimit[S]) Allow() bool {
+	return t.AllowN(time.Now(), 1)
+}
+
+// AllowN reports whether n events may happen at time now.
+// Use this method if you intend to drop / skip events that exceed the rate.
+// Otherwise, use Reserve or Wait.
+func (t *TokenLimit[S]) AllowN(now time.Time, n int) bool {
+	return t.AllowNCtx(context.Background(), now, n)
+}
+
+// AllowCtx is shorthand for AllowNCtx(ctx, time.Now(), 1).
+func (t *TokenLimit[S]) AllowCtx(ctx context.Context) bool {
+	return t.AllowNCtx(ctx, time.Now(), 1)
+}
+
+// AllowNCtx reports whether n events may happen at time now with context.
+func (t *TokenLimit[S]) AllowNCtx(ctx context.Context, now time.Time, n int) bool {
+	return t.reserveN(ctx, now, n, 0).OK()
+}
+
+// TryReserveN reserves n tokens only if they are available at time now, same as AllowNCtx,
+// but the returned TokenReservation reports the remaining tokens, or the time to retry if not OK.
+func (t *TokenLimit[S]) TryReserveN(ctx context.Context, now time.Time, n int) *TokenReservation {
+	return t.reserveN(ctx, now, n, 0)
+}
+
+// Limit returns the maximum overall event rate.
+func (t *TokenLimit[S]) Limit() float64 { return t.limits.Load().rate }
+
+// Burst returns the maximum burst size.
+func (t *TokenLimit[S]) Burst() int { return t.limits.Load().burst }
+
+// Update sets the rate and burst atomically, including the in-process rescue limiter,
+// the tokens in the storage are refilled with the new rate and capped to the new burst on the next take.
+func (t *TokenLimit[S]) Update(rate float64, burst int) {
+	t.limits.Store(&tokenLimits{rate: rate, burst: burst})
+	t.opt.updateRescueLimiter(t.rescueLimiter, rate, burst)
+}
+
+// Reserve is shorthand for ReserveN(ctx, time.Now(), 1).
+func (t *TokenLimit[S]) Reserve(ctx context.Context) *TokenReservation {
+	return t.ReserveN(ctx, time.Now(), 1)
+}
+
+// ReserveN returns a TokenReservation that indicates how long the caller must wait before n events happen.
+// The TokenLimit takes this Reservation into account when allowing future events.
+// The returned Reservation’s OK() method returns false if n exceeds the TokenLimit's burst.
+// NOTE: the reserved tokens can not be canceled.
+func (t *TokenLimit[S]) ReserveN(ctx context.Context, now time.Time, n int) *TokenReservation {
+	return t.reserveN(ctx, now, n, -1)
+}
+
+// Wait is shorthand for WaitN(ctx, 1).
+func (t *TokenLimit[S]) Wait(ctx context.Context) error {
+	return t.WaitN(ctx, 1)
+}
+
+// WaitN blocks until TokenLimit permits n events to happen.
+// It returns an error if n exceeds the TokenLimit's burst, the Context is
+// canceled, or the expected wait time exceeds the Context's Deadline.
+// NOTE: the reserved tokens will not be restored if the Context is canceled while waiting.
+func (t *TokenLimit[S]) WaitN(ctx context.Context, n int) error {
+	if burst := t.Burst(); n > burst {
+		return fmt.Errorf("limit: WaitN(n=%d) exceeds limiter's burst %d", n, burst)
+	}
+	// Check if ctx is already cancelled
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	now := time.Now()
+	waitLimit := time.Duration(-1)
+	if deadline, ok := ctx.Deadline(); ok {
+		waitLimit = max(deadline.Sub(now), 0)
+	}
+	r := t.reserveN(ctx, now, n, waitLimit)
+	if !r.OK() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("limit: WaitN(n=%d) would exceed context deadline", n)
+	}
+	delay := r.DelayFrom(now)
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *TokenLimit[S]) reserveN(ctx context.Context, now time.Time, n int, maxWait time.Duration) *TokenReservation {
+	if !t.isAlive() {
+		return t.opt.fallback(ctx, t.key, now, n, maxWait, ErrStoreUnavailable, t.rescueLimiter)
+	}
+
+	limits := t.limits.Load()
+	tb, err := t.store.Take(ctx, t.key, limits.rate, limits.burst, now, n, maxWait)
+	if err == nil && len(tb) != 3 {
+		err = ErrUnknownCode
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return newUnknownTokenReservation(false, time.Time{})
+		}
+		t.startMonitor(err)
+		return t.opt.fallback(ctx, t.key, now, n, maxWait, err, t.rescueLimiter)
+	}
+	return newTokenReservation(now, n, limits.burst, tb)
+}
+
+// newTokenReservation returns a TokenReservation from the result of TokenStorage.Take.
+func newTokenReservation(now time.Time, n, burst int, tb []int64) *TokenReservation {
+	wait := time.Duration(tb[1]) * time.Microsecond
+	r := &TokenReservation{
+		ok:         tb[0] == 1,
+		timeToAct:  now.Add(wait),
+		remaining:  int(max(tb[2], 0)),
+		retryAfter: -1,
+	}
+	if !r.ok && n <= burst {
+		r.retryAfter = wait
+	}
+	return r
+}
+
+// newUnknownTokenReservation returns a TokenReservation which remaining tokens and retry after are unknown.
+func newUnknownTokenReservation(ok bool, timeToAct time.Time) *TokenReservation {
+	return &TokenReservation{
+		ok:         ok,
+		timeToAct:  timeToAct,
+		remaining:  -1,
+		retryAfter: -1,
+	}
+}
+
+// TokenReservation holds information about events that are permitted by a TokenLimit to happen after a delay.
+type TokenReservation struct {
+	ok         bool
+	timeToAct  time.Time
+	remaining  int
+	retryAfter time.Duration
+}
+
+// OK returns whether the limiter can provide the requested number of tokens
+// within the maximum wait time.
+func (r *TokenReservation) OK() bool {
+	return r.ok
+}
+
+// Remaining returns the remaining tokens after the reservation, -1 if unknown,
+// such as the storage is unavailable.
+func (r *TokenReservation) Remaining() int {
+	return r.remaining
+}
+
+// RetryAfter returns the time until the requested tokens will be available if not OK,
+// -1 if OK, never available or unknown.
+func (r *TokenReservation) RetryAfter() time.Duration {
+	return r.retryAfter
+}
+
+// Delay is shorthand for DelayFrom(time.Now()).
+func (r *TokenReservation) Delay() time.Duration {
+	return r.DelayFrom(time.Now())
+}
+
+// DelayFrom returns the duration for which the reservation holder must wait
+// before taking the reserved action. Zero duration means act immediately.
+// InfDuration means the limiter cannot grant the tokens requested in this
+// Reservation within the maximum wait time.
+func (r *TokenReservation) DelayFrom(now time.Time) time.Duration {
+	if !r.ok {
+		return xrate.InfDuration
+	}
+	delay := r.timeToAct.Sub(now)
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
@@ -0,0 +1,175 @@
+package limit
+
+import (
+	"context"
+	"log"
+)
+
+// DefaultRescueCacheSize default max number of the in-process rescue limiters of KeyedTokenLimit.
+const DefaultRescueCacheSize = 1024
+
+// FailurePolicy the policy of TokenLimit and KeyedTokenLimit when the storage is unavailable.
+type FailurePolicy int
+
+const (
+	// FailurePolicyLocalFallback use the in-process limiter for rescue.
+	FailurePolicyLocalFallback FailurePolicy = iota
+	// FailurePolicyOpen allow all events.
+	FailurePolicyOpen
+	// FailurePolicyClosed deny all events.
+	FailurePolicyClosed
+	// FailurePolicyCustom decide by the failure handler, see WithFailureHandler.
+	FailurePolicyCustom
+)
+
+// String implements fmt.Stringer.
+func (p FailurePolicy) String() string {
+	switch p {
+	case FailurePolicyLocalFallback:
+		return "local-fallback"
+	case FailurePolicyOpen:
+		return "fail-open"
+	case FailurePolicyClosed:
+		return "fail-closed"
+	case FailurePolicyCustom:
+		return "custom"
+	default:
+		return "unknown"
+	}
+}
+
+// FailureHandler decides whether n events of the key may happen when the storage is unavailable.
+type FailureHandler func(ctx context.Context, key string, n int, err error) bool
+
+// DegradedEvent the event when the storage is unavailable, or available again.
+type DegradedEvent struct {
+	// Degraded true if the storage is unavailable, false if it is available again.
+	Degraded bool
+	// Err the error causes degraded, nil when available again.
+	Err error
+	// Policy the failure policy in use.
+	Policy FailurePolicy
+}
+
+// TokenLimitOptionSetter option setter for TokenLimit and KeyedTokenLimit
+type TokenLimitOptionSetter interface {
+	setRescueCacheSize(n int)
+	setRescueReplicas(n int)
+	setFailurePolicy(p FailurePolicy)
+	setFailureHandler(h FailureHandler)
+	setDegradedHook(f func(DegradedEvent))
+	setHealthChecker(h *HealthChecker)
+}
+
+// TokenLimitOption defines the method to customize a TokenLimit and KeyedTokenLimit.
+type TokenLimitOption func(l TokenLimitOptionSetter)
+
+// WithRescueCacheSize set the max number of per-key in-process rescue limiters,
+// the least recently used one will be evicted, only for KeyedTokenLimit.
+// default: DefaultRescueCacheSize
+func WithRescueCacheSize(n int) TokenLimitOption {
+	return func(l TokenLimitOptionSetter) {
+		l.setRescueCacheSize(n)
+	}
+}
+
+// WithRescueReplicas set the number of service replicas, the in-process rescue limiter
+// allows rate/n events and permits bursts of burst/n tokens, so that all replicas
+// are not n times too generous than the storage one.
+// default: 1
+func WithRescueReplicas(n int) TokenLimitOption {
+	return func(l TokenLimitOptionSetter) {
+		l.setRescueReplicas(n)
+	}
+}
+
+// WithFailurePolicy set the failure policy when the storage is unavailable.
+// default: FailurePolicyLocalFallback
+func WithFailurePolicy(p FailurePolicy) TokenLimitOption {
+	return func(l TokenLimitOptionSetter) {
+		l.setFailurePolicy(p)
+	}
+}
+
+// WithFailureHandler set a custom failure handler when the storage is unavailable,
+// it implies FailurePolicyCustom.
+func WithFailureHandler(h FailureHandler) TokenLimitOption {
+	return func(l TokenLimitOptionSetter) {
+		if h != nil {
+			l.setFailureHandler(h)
+			l.setFailurePolicy(FailurePolicyCustom)
+		}
+	}
+}
+
+// WithDegradedHook set the hook which called when the storage is unavailable, or available again.
+// default: log the event, once per change for the limiters sharing a HealthChecker with the same failure policy.
+func WithDegradedHook(f func(DegradedEvent)) TokenLimitOption {
+	return func(l TokenLimitOptionSetter) {
+		if f != nil {
+			l.setDegradedHook(f)
+		}
+	}
+}
+
+// WithHealthChecker set the HealthChecker of the storage, the caller should close it.
+// default: the limiters of the same storage share one HealthChecker, which closed when all limiters closed.
+func WithHealthChecker(h *HealthChecker) TokenLimitOption {
+	return func(l TokenLimitOptionSetter) {
+		if h != nil {
+			l.setHealthChecker(h)
+		}
+	}
+}
+
+// tokenLimitOption the options of TokenLimit and KeyedTokenLimit
+type tokenLimitOption struct {
+	rescueCacheSize int
+	rescueReplicas  int
+	failurePolicy   FailurePolicy
+	failureHandler  FailureHandler
+	degradedHook    func(DegradedEvent) // nil means logDegradedEvent
+	healthChecker   *HealthChecker
+}
+
+func newTokenLimitOption(opts ...TokenLimitOption) *tokenLimitOption {
+	o := &tokenLimitOption{
+		rescueCacheSize: DefaultRescueCacheSize,
+		rescueReplicas:  1,
+		failurePolicy:   FailurePolicyLocalFallback,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	switch {
+	case o.failurePolicy == FailurePolicyOpen,
+		o.failurePolicy == FailurePolicyClosed,
+		o.failurePolicy == FailurePolicyCustom && o.failureHandler != nil:
+	default:
+		o.failurePolicy = FailurePolicyLocalFallback
+	}
+	return o
+}
+
+func (o *tokenLimitOption) setRescueCacheSize(n int) {
+	if n > 0 {
+		o.rescueCacheSize = n
+	}
+}
+func (o *tokenLimitOption) setRescueReplicas(n int) {
+	if n > 0 {
+		o.rescueReplicas = n
+	}
+}
+func (o *tokenLimitOption) setFailurePolicy(p FailurePolicy)      { o.failurePolicy = p }
+func (o *tokenLimitOption) setFailureHandler(h FailureHandler)    { o.failureHandler = h }
+func (o *tokenLimitOption) setDegradedHook(f func(DegradedEvent)) { o.degradedHook = f }
+func (o *tokenLimitOption) setHealthChecker(h *HealthChecker)     { o.healthChecker = h }
+
+func logDegradedEvent(e DegradedEvent) {
+	if e.Degraded {
+		log.Printf("fail to use rate limiter: %s, use %s policy for rescue", e.Err, e.Policy)
+	} else {
+		log.Printf("rate limiter storage is available again")
+	}
+}
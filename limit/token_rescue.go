@@ -0,0 +1,109 @@
+package limit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	xrate "golang.org/x/time/rate"
+)
+
+// newRescueLimiter returns an in-process rescue limiter, which shares rate and burst with the replicas.
+func (o *tokenLimitOption) newRescueLimiter(rate float64, burst int) *xrate.Limiter {
+	return xrate.NewLimiter(o.rescueLimits(rate, burst))
+}
+
+// updateRescueLimiter sets the rate and burst of the in-process rescue limiter.
+func (o *tokenLimitOption) updateRescueLimiter(l *xrate.Limiter, rate float64, burst int) {
+	r, b := o.rescueLimits(rate, burst)
+	l.SetLimit(r)
+	l.SetBurst(b)
+}
+
+// rescueLimits returns the rate and burst of the in-process rescue limiter, shared with the replicas.
+func (o *tokenLimitOption) rescueLimits(rate float64, burst int) (xrate.Limit, int) {
+	n := o.rescueReplicas
+	return xrate.Limit(rate / float64(n)), max(1, (burst+n-1)/n)
+}
+
+// fallback reserve n tokens follow the failure policy when the storage is unavailable.
+func (o *tokenLimitOption) fallback(ctx context.Context, key string, now time.Time, n int, maxWait time.Duration, err error, rescue *xrate.Limiter) *TokenReservation {
+	switch o.failurePolicy {
+	case FailurePolicyOpen:
+		return newUnknownTokenReservation(true, now)
+	case FailurePolicyClosed:
+		return newUnknownTokenReservation(false, time.Time{})
+	case FailurePolicyCustom:
+		return newUnknownTokenReservation(o.failureHandler(ctx, key, n, err), now)
+	default:
+		return rescueReserveN(rescue, now, n, maxWait)
+	}
+}
+
+// rescueReserveN reserve n tokens from the in-process rescue limiter within maxWait, maxWait < 0 means no limit.
+func rescueReserveN(l *xrate.Limiter, now time.Time, n int, maxWait time.Duration) *TokenReservation {
+	r := l.ReserveN(now, n)
+	if !r.OK() {
+		return newUnknownTokenReservation(false, time.Time{})
+	}
+	delay := r.DelayFrom(now)
+	if maxWait >= 0 && delay > maxWait {
+		r.CancelAt(now)
+		return newUnknownTokenReservation(false, time.Time{})
+	}
+	return newUnknownTokenReservation(true, now.Add(delay))
+}
+
+// storeHealth the health of the storage, the HealthChecker is shared by the limiters of the same storage.
+type storeHealth struct {
+	health      *HealthChecker
+	release     func()
+	unsubscribe func()
+	closeOnce   sync.Once
+}
+
+// logDegradedHookKey the key of the default degraded hook subscribed to the HealthChecker.
+type logDegradedHookKey struct {
+	policy FailurePolicy
+}
+
+func newStoreHealth(store TokenStorage, opt *tokenLimitOption) *storeHealth {
+	s := &storeHealth{
+		health:  opt.healthChecker,
+		release: func() {},
+	}
+	if s.health == nil {
+		s.health, s.release = acquireHealthChecker(store)
+	}
+	// the limiters sharing the HealthChecker with the same policy log one event per change,
+	// the hooks set by the caller are called for each limiter.
+	var key any
+	hook := opt.degradedHook
+	if hook == nil {
+		key = logDegradedHookKey{policy: opt.failurePolicy}
+		hook = logDegradedEvent
+	}
+	s.unsubscribe = s.health.subscribe(key, func(healthy bool, err error) {
+		hook(DegradedEvent{
+			Degraded: !healthy,
+			Err:      err,
+			Policy:   opt.failurePolicy,
+		})
+	})
+	return s
+}
+
+func (s *storeHealth) isAlive() bool {
+	return s.health.Healthy()
+}
+
+func (s *storeHealth) startMonitor(err error) {
+	s.health.MarkUnhealthy(err)
+}
+
+func (s *storeHealth) close() {
+	s.closeOnce.Do(func() {
+		s.unsubscribe()
+		s.release()
+	})
+}
@@ -0,0 +1,159 @@
+package limit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errStoreUnavailable = errors.New("store unavailable")
+
+type fakeTokenStore struct {
+	alive atomic.Bool
+	pings atomic.Int32
+}
+
+func (f *fakeTokenStore) Take(context.Context, string, float64, int, time.Time, int, time.Duration) ([]int64, error) {
+	if !f.alive.Load() {
+		return nil, errStoreUnavailable
+	}
+	return []int64{1, 0, 0}, nil
+}
+
+func (f *fakeTokenStore) Ping(context.Context) error {
+	f.pings.Add(1)
+	if !f.alive.Load() {
+		return errStoreUnavailable
+	}
+	return nil
+}
+
+func TestTokenLimit_Rescue(t *testing.T) {
+	const (
+		rate  = 5
+		burst = 10
+	)
+	store := &fakeTokenStore{}
+	l := NewTokenLimit(rate, burst, "tokenlimit", store)
+
+	var allowed int
+	for i := 0; i < burst*2; i++ {
+		if l.Allow() {
+			allowed++
+		}
+		// make sure start monitor more than once doesn't matter
+		l.startMonitor(errStoreUnavailable)
+	}
+	assert.Equal(t, burst, allowed)
+	assert.False(t, l.isAlive())
+
+	store.alive.Store(true)
+	assert.Eventually(t, l.isAlive, time.Second, TokenLimitPingInterval)
+	assert.True(t, l.Allow())
+}
+
+func TestTokenLimit_RescueReserve(t *testing.T) {
+	const (
+		rate  = 5
+		burst = 10
+	)
+	l := NewTokenLimit(rate, burst, "tokenlimit", &fakeTokenStore{})
+	now := time.Now()
+
+	r := l.ReserveN(context.Background(), now, burst)
+	assert.True(t, r.OK())
+	assert.Zero(t, r.DelayFrom(now))
+
+	assert.False(t, l.AllowNCtx(context.Background(), now, 1))
+
+	r = l.ReserveN(context.Background(), now, rate)
+	assert.True(t, r.OK())
+	assert.Equal(t, time.Second, r.DelayFrom(now))
+}
+
+func TestTokenLimit_FailurePolicy(t *testing.T) {
+	const (
+		rate  = 5
+		burst = 10
+	)
+	var events []DegradedEvent
+	var mu sync.Mutex
+	hook := func(e DegradedEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}
+
+	t.Run("fail open", func(t *testing.T) {
+		l := NewTokenLimit(rate, burst, "tokenlimit", &fakeTokenStore{}, WithFailurePolicy(FailurePolicyOpen))
+		for i := 0; i < burst*2; i++ {
+			assert.True(t, l.Allow())
+		}
+	})
+	t.Run("fail closed", func(t *testing.T) {
+		l := NewTokenLimit(rate, burst, "tokenlimit", &fakeTokenStore{}, WithFailurePolicy(FailurePolicyClosed))
+		assert.False(t, l.Allow())
+		assert.Error(t, l.Wait(context.Background()))
+	})
+	t.Run("custom", func(t *testing.T) {
+		l := NewKeyedTokenLimit(rate, burst, &fakeTokenStore{},
+			WithFailureHandler(func(_ context.Context, key string, n int, err error) bool {
+				assert.Error(t, err)
+				return key == "first"
+			}),
+		)
+		assert.True(t, l.Allow(context.Background(), "first"))
+		assert.False(t, l.Allow(context.Background(), "second"))
+	})
+	t.Run("local fallback with replicas", func(t *testing.T) {
+		store := &fakeTokenStore{}
+		l := NewTokenLimit(rate, burst, "tokenlimit", store,
+			WithRescueReplicas(3),
+			WithDegradedHook(hook),
+		)
+		var allowed int
+		for i := 0; i < burst*2; i++ {
+			if l.Allow() {
+				allowed++
+			}
+		}
+		assert.Equal(t, 4, allowed)
+
+		store.alive.Store(true)
+		assert.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(events) == 2
+		}, time.Second, TokenLimitPingInterval)
+		assert.True(t, l.isAlive())
+
+		mu.Lock()
+		defer mu.Unlock()
+		if assert.Len(t, events, 2) {
+			assert.True(t, events[0].Degraded)
+			assert.ErrorIs(t, events[0].Err, errStoreUnavailable)
+			assert.Equal(t, FailurePolicyLocalFallback, events[0].Policy)
+			assert.False(t, events[1].Degraded)
+			assert.NoError(t, events[1].Err)
+		}
+	})
+}
+
+func TestTokenLimit_UpdateRescue(t *testing.T) {
+	store := &fakeTokenStore{}
+	l := NewTokenLimit(5, 10, "tokenlimit", store)
+	l.Update(5, 2)
+
+	var allowed int
+	for i := 0; i < 10; i++ {
+		if l.Allow() {
+			allowed++
+		}
+	}
+	assert.Equal(t, 2, allowed)
+}
@@ -8,3 +8,25 @@ type RunValue struct {
 	Count int64         // count value
 	TTL   time.Duration // TTL in seconds
 }
+
+// TakeResult the result of PeriodLimit take.
+type TakeResult struct {
+	// State the permit state.
+	State PeriodLimitState
+	// Limit the quota during a period.
+	Limit int
+	// Remaining the remaining permits during the period.
+	Remaining int
+	// ResetAfter the time until the quota resets, -1 if never.
+	ResetAfter time.Duration
+	// RetryAfter the time until the requested permits will be allowed, -1 if allowed or never allowed.
+	RetryAfter time.Duration
+}
+
+// milliDuration returns duration of v milliseconds, keep -1 as it is.
+func milliDuration(v int64) time.Duration {
+	if v < 0 {
+		return -1
+	}
+	return time.Duration(v) * time.Millisecond
+}